@@ -0,0 +1,93 @@
+// Package cbor is the runtime of the CBOR code generated by polygen, a small self-contained
+// encoder and decoder of CBOR (RFC 8949) for the Go types polymorphic subtypes are made of.
+//
+// Go values are mapped like encoding/json maps them to JSON: structs are encoded as maps with text keys,
+// named by the "cbor" struct tag or else the "json" one (including the omitempty option and "-"),
+// embedded structs are flattened, nil pointers, slices, maps and interfaces are encoded as null,
+// []byte as a byte string, and types implementing Marshaler or encoding.TextMarshaler encode themselves.
+// Integers use the shortest form, float32 and float64 values are encoded as single and double precision.
+// Decoding accepts any well-formed input, including indefinite lengths, half precision floats and tags,
+// which are ignored. Decoding into an empty interface produces int64, uint64, float64, bool, string,
+// []byte, []any, map[string]any or nil.
+//
+// # Tagging convention
+//
+// The MarshalCBOR method generated for a polymorphic type encodes its subtype as a map whose first entry
+// is the discriminator: a text string key, the discriminator name, holding the type name as a text string,
+// or as an integer for int discriminators. The entries of the subtype follow. A nested discriminator (meta.type)
+// is the first entry of a nested map instead, and a nil subtype is encoded as null.
+package cbor
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Marshaler is implemented by types that encode themselves as a single CBOR item.
+type Marshaler interface {
+	MarshalCBOR() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types that decode themselves from a single CBOR item.
+type Unmarshaler interface {
+	UnmarshalCBOR(data []byte) error
+}
+
+// Marshal returns the CBOR encoding of v.
+func Marshal(v any) ([]byte, error) {
+	var e encodeState
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+
+	return e.buf, nil
+}
+
+// Unmarshal decodes the CBOR item data into the value pointed to by v, ignoring unknown struct fields.
+func Unmarshal(data []byte, v any) error {
+	return unmarshal(data, v, false)
+}
+
+// UnmarshalStrict is like Unmarshal but fails on map keys that match no struct field.
+func UnmarshalStrict(data []byte, v any) error {
+	return unmarshal(data, v, true)
+}
+
+func unmarshal(data []byte, v any, strict bool) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("cbor: Unmarshal needs a non-nil pointer")
+	}
+
+	if err := checkItem(data); err != nil {
+		return err
+	}
+
+	d := decodeState{data: data, strict: strict}
+
+	return d.decode(rv.Elem())
+}
+
+// RawMessage is an encoded CBOR item, used to delay decoding or to precompute an encoding.
+type RawMessage []byte
+
+// MarshalCBOR returns m as the encoding of itself, or null if m is nil.
+func (m RawMessage) MarshalCBOR() ([]byte, error) {
+	if m == nil {
+		return []byte{simpleNull}, nil
+	}
+
+	return m, nil
+}
+
+// UnmarshalCBOR sets *m to a copy of data.
+func (m *RawMessage) UnmarshalCBOR(data []byte) error {
+	*m = append((*m)[:0], data...)
+
+	return nil
+}
+
+// IsNull reports whether data is the encoding of null, or of undefined which is decoded alike.
+func IsNull(data []byte) bool {
+	return len(data) == 1 && (data[0] == simpleNull || data[0] == simpleUndefined)
+}
@@ -0,0 +1,360 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type Named struct {
+	Name string `cbor:"name" json:"title"`
+}
+
+type record struct {
+	point
+	*Named
+	Label   string            `json:"label,omitempty"`
+	Skipped string            `json:"-"`
+	Tags    []string          `json:"tags"`
+	Attrs   map[string]any    `json:"attrs,omitempty"`
+	Data    []byte            `json:"data,omitempty"`
+	When    time.Time         `json:"when"`
+	Raw     RawMessage        `json:"raw,omitempty"`
+	Counts  map[int]float32   `json:"counts,omitempty"`
+	Inner   *point            `json:"inner"`
+	Extra   map[string]string `json:"extra,omitempty"`
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"nil", nil, "f6"},
+		{"false", false, "f4"},
+		{"true", true, "f5"},
+		{"small int", 10, "0a"},
+		{"uint8 int", 24, "1818"},
+		{"uint16 int", 1000, "1903e8"},
+		{"uint32 int", 1000000, "1a000f4240"},
+		{"uint64 int", uint64(1000000000000), "1b000000e8d4a51000"},
+		{"negative int", -1000, "3903e7"},
+		{"float32", float32(1.5), "fa3fc00000"},
+		{"float64", 1.1, "fb3ff199999999999a"},
+		{"text", "IETF", "6449455446"},
+		{"bytes", []byte{1, 2, 3, 4}, "4401020304"},
+		{"array", [3]int{1, 2, 3}, "83010203"},
+		{"nil slice", []int(nil), "f6"},
+		{"nil pointer", (*int)(nil), "f6"},
+		{"map sorted by encoded keys", map[string]int{"b": 2, "a": 1, "aa": 3}, "a3 6161 01 6162 02 626161 03"},
+		{"struct", point{X: 1, Y: -2}, "a2 6178 01 6179 21"},
+		{"raw message", RawMessage{0x18, 0x64}, "1864"},
+		{"nil raw message", RawMessage(nil), "f6"},
+		{"text marshaler", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "74 323032342d30312d30325430333a30343a30355a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			if want := mustHex(t, tt.want); !bytes.Equal(data, want) {
+				t.Errorf("Marshal() = %x, want %x", data, want)
+			}
+		})
+	}
+}
+
+func TestMarshalStruct(t *testing.T) {
+	data, err := Marshal(record{
+		point:   point{X: 1, Y: 2},
+		Named:   &Named{Name: "n"},
+		Skipped: "skipped",
+	})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := map[string]any{
+		"x":     int64(1),
+		"y":     int64(2),
+		"name":  "n",
+		"tags":  nil,
+		"when":  "0001-01-01T00:00:00Z",
+		"inner": nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %#v, want %#v", got, want)
+	}
+
+	// A nil embedded pointer has no fields to encode
+	data, err = Marshal(record{})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	got = nil
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if _, ok := got["name"]; ok {
+		t.Errorf("Unmarshal() = %v, want no name", got)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	type cyclic struct {
+		Next *cyclic
+	}
+
+	loop := &cyclic{}
+	loop.Next = loop
+
+	tests := []struct {
+		name    string
+		value   any
+		wantErr string
+	}{
+		{"channel", make(chan int), "unsupported type chan int"},
+		{"map key", map[[2]int]int{{1, 2}: 3}, "unsupported map key type [2]int"},
+		{"cycle", loop, "exceeded max depth"},
+		{"invalid raw message", RawMessage{0x82, 0x01}, "invalid output of MarshalCBOR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Marshal(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Marshal() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := record{
+		point:  point{X: -3, Y: 4},
+		Named:  &Named{Name: "n"},
+		Label:  "label",
+		Tags:   []string{"a", "b"},
+		Attrs:  map[string]any{"layer": int64(1), "list": []any{"x", true, 1.5, nil}},
+		Data:   []byte("data"),
+		When:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Raw:    RawMessage{0x83, 0x01, 0x02, 0x03},
+		Counts: map[int]float32{-1: 0.5, 2: 1},
+		Inner:  &point{X: 5},
+		Extra:  map[string]string{"k": "v"},
+	}
+
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var out record
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Unmarshal() = %+v, want %+v", out, in)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		into any
+		want any
+	}{
+		{"half float", "f93e00", new(float64), 1.5},
+		{"half float subnormal", "f90001", new(float64), math.Ldexp(1, -24)},
+		{"half float infinity", "f9fc00", new(float64), math.Inf(-1)},
+		{"single float", "fa47c35000", new(float32), float32(100000)},
+		{"int into float", "3903e7", new(float64), -1000.0},
+		{"negative int", "3903e7", new(int16), int16(-1000)},
+		{"large uint", "1bffffffffffffffff", new(uint64), uint64(math.MaxUint64)},
+		{"indefinite text", "7f 627374 627265 ff", new(string), "stre"},
+		{"indefinite bytes", "5f 4201 02 4103 ff", new([]byte), []byte{1, 2, 3}},
+		{"indefinite array", "9f 01 02 ff", new([]int), []int{1, 2}},
+		{"empty indefinite array", "9f ff", new([]int), []int{}},
+		{"indefinite map", "bf 6178 01 6179 02 ff", new(point), point{X: 1, Y: 2}},
+		{"tag", "c1 1a514b67b0", new(int64), int64(1363896240)},
+		{"case-insensitive field", "a1 6158 07", new(point), point{X: 7}},
+		{"unknown field", "a2 617a 01 6178 02", new(point), point{X: 2}},
+		{"integer key", "a2 01 02 6178 03", new(point), point{X: 3}},
+		{"array shorter than target", "81 01", new([2]int), [2]int{1, 0}},
+		{"array longer than target", "83 01 02 03", new([2]int), [2]int{1, 2}},
+		{"map with integer keys", "a1 20 63616263", new(map[int]string), map[int]string{-1: "abc"}},
+		{"null into pointer", "f6", new(*int), (*int)(nil)},
+		{"null into int", "f6", new(int), 0},
+		{"undefined into slice", "f7", new([]int), []int(nil)},
+		{"any", "a2 6161 20 6162 82 f5 f6", new(any), map[string]any{"a": int64(-1), "b": []any{true, nil}}},
+		{"large uint any", "1bffffffffffffffff", new(any), uint64(math.MaxUint64)},
+		{"raw message", "82 01 02", new(RawMessage), RawMessage{0x82, 0x01, 0x02}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(mustHex(t, tt.data), tt.into); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if got := reflect.ValueOf(tt.into).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		into    any
+		strict  bool
+		wantErr string
+	}{
+		{"empty", "", new(int), false, "unexpected end of input"},
+		{"truncated", "1903", new(int), false, "unexpected end of input"},
+		{"trailing data", "0102", new(int), false, "unexpected data after top-level item"},
+		{"reserved info", "1c", new(int), false, "malformed initial byte 0x1c"},
+		{"stray break", "ff", new(int), false, "unexpected break"},
+		{"huge count", "9b7fffffffffffffff", new([]int), false, "unexpected end of input"},
+		{"bad chunk", "5f 6161 ff", new([]byte), false, "malformed chunk of byte string"},
+		{"type mismatch", "6161", new(int), false, "cannot unmarshal text string at offset 0 into Go value of type int"},
+		{"overflow", "190100", new(uint8), false, "value 256 at offset 0 overflows uint8"},
+		{"negative into uint", "20", new(uint), false, "cannot unmarshal negative integer"},
+		{"unknown field", "a1 617a 01", new(point), true, `unknown field "z"`},
+		{"non-text key into any", "a1 01 02", new(any), false, "cannot unmarshal map key of type int64"},
+		{"non-pointer", "01", 1, false, "needs a non-nil pointer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unmarshal := Unmarshal
+			if tt.strict {
+				unmarshal = UnmarshalStrict
+			}
+
+			err := unmarshal(mustHex(t, tt.data), tt.into)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Unmarshal() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnmarshalDepth(t *testing.T) {
+	data := bytes.Repeat([]byte{0x81}, maxDepth+1)
+	data = append(data, 0x01)
+
+	var v any
+	if err := Unmarshal(data, &v); err == nil || !strings.Contains(err.Error(), "exceeded max depth") {
+		t.Errorf("Unmarshal() error = %v, want max depth error", err)
+	}
+}
+
+func TestInsertPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		path  []string
+		value string
+		want  string
+	}{
+		{"empty map", "a0", []string{"type"}, "6161", "a1 6474797065 6161"},
+		{"prepended", "a1 6178 01", []string{"type"}, "6161", "a2 6474797065 6161 6178 01"},
+		{"replaced", "a2 6178 01 6474797065 6162", []string{"type"}, "6161", "a2 6474797065 6161 6178 01"},
+		{"nested created", "a1 6178 01", []string{"meta", "type"}, "6161", "a2 646d657461 a1 6474797065 6161 6178 01"},
+		{"nested merged", "a2 6178 01 646d657461 a1 6178 02", []string{"meta", "type"}, "6161", "a2 6178 01 646d657461 a2 6474797065 6161 6178 02"},
+		{"nested null", "a1 646d657461 f6", []string{"meta", "type"}, "6161", "a1 646d657461 a1 6474797065 6161"},
+		{"indefinite map", "bf 6178 01 ff", []string{"type"}, "01", "a2 6474797065 01 6178 01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InsertPath(mustHex(t, tt.data), tt.path, mustHex(t, tt.value))
+			if err != nil {
+				t.Fatalf("InsertPath() error = %v", err)
+			}
+
+			if want := mustHex(t, tt.want); !bytes.Equal(got, want) {
+				t.Errorf("InsertPath() = %x, want %x", got, want)
+			}
+		})
+	}
+
+	if _, err := InsertPath(mustHex(t, "a1 646d657461 01"), []string{"meta", "type"}, mustHex(t, "01")); err == nil || !strings.Contains(err.Error(), "at meta: cbor: expected map, got unsigned integer") {
+		t.Errorf("InsertPath() error = %v, want error for a non-map member", err)
+	}
+}
+
+func TestExtractPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		path      []string
+		wantValue string
+		wantRest  string
+	}{
+		{"first", "a2 6474797065 6161 6178 01", []string{"type"}, "6161", "a1 6178 01"},
+		{"last", "a2 6178 01 6474797065 6161", []string{"type"}, "6161", "a1 6178 01"},
+		{"missing", "a1 6178 01", []string{"type"}, "", "a1 6178 01"},
+		{"nested", "a2 646d657461 a2 6474797065 6161 6178 02 6178 01", []string{"meta", "type"}, "6161", "a2 646d657461 a1 6178 02 6178 01"},
+		{"nested emptied", "a2 646d657461 a1 6474797065 6161 6178 01", []string{"meta", "type"}, "6161", "a1 6178 01"},
+		{"nested missing", "a1 646d657461 a1 6178 02", []string{"meta", "type"}, "", "a1 646d657461 a1 6178 02"},
+		{"nested null", "a1 646d657461 f6", []string{"meta", "type"}, "", "a1 646d657461 f6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, rest, err := ExtractPath(mustHex(t, tt.data), tt.path)
+			if err != nil {
+				t.Fatalf("ExtractPath() error = %v", err)
+			}
+
+			if want := mustHex(t, tt.wantValue); !bytes.Equal(value, want) || (value == nil) != (tt.wantValue == "") {
+				t.Errorf("ExtractPath() value = %x, want %x", value, want)
+			}
+
+			if want := mustHex(t, tt.wantRest); !bytes.Equal(rest, want) {
+				t.Errorf("ExtractPath() rest = %x, want %x", rest, want)
+			}
+		})
+	}
+
+	if _, _, err := ExtractPath(mustHex(t, "81 01"), []string{"type"}); err == nil || !strings.Contains(err.Error(), "expected map, got array") {
+		t.Errorf("ExtractPath() error = %v, want error for a non-map", err)
+	}
+}
@@ -0,0 +1,663 @@
+package cbor
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+)
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+var majorNames = [...]string{"unsigned integer", "negative integer", "byte string", "text string", "array", "map", "tag", "simple value"}
+
+type decodeState struct {
+	data   []byte
+	off    int
+	strict bool
+	depth  int
+}
+
+// checkItem reports an error unless data is exactly one well-formed item.
+func checkItem(data []byte) error {
+	d := decodeState{data: data}
+	if err := d.skip(); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("cbor: unexpected end of input: %w", err)
+		}
+
+		return err
+	}
+
+	if d.off != len(data) {
+		return fmt.Errorf("cbor: unexpected data after top-level item at offset %d", d.off)
+	}
+
+	return nil
+}
+
+// head reads the initial byte of the next item and its argument, which is the value, length or count
+// of the item depending on its major type. info is the additional information of the initial byte.
+func (d *decodeState) head() (major, info byte, arg uint64, err error) {
+	if d.off >= len(d.data) {
+		return 0, 0, 0, io.ErrUnexpectedEOF
+	}
+
+	major, info = d.data[d.off]>>5, d.data[d.off]&0x1f
+	d.off++
+
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info < 28:
+		n := 1 << (info - 24)
+		if len(d.data)-d.off < n {
+			return 0, 0, 0, io.ErrUnexpectedEOF
+		}
+
+		switch n {
+		case 1:
+			arg = uint64(d.data[d.off])
+		case 2:
+			arg = uint64(binary.BigEndian.Uint16(d.data[d.off:]))
+		case 4:
+			arg = uint64(binary.BigEndian.Uint32(d.data[d.off:]))
+		default:
+			arg = binary.BigEndian.Uint64(d.data[d.off:])
+		}
+
+		d.off += n
+	case info == infoIndefinite && major != majorUint && major != majorNegInt && major != majorTag:
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: malformed initial byte 0x%02x at offset %d", d.data[d.off-1], d.off-1)
+	}
+
+	return major, info, arg, nil
+}
+
+// atBreak reports whether the next byte ends an item of indefinite length, consuming it if so.
+func (d *decodeState) atBreak() (bool, error) {
+	if d.off >= len(d.data) {
+		return false, io.ErrUnexpectedEOF
+	}
+
+	if d.data[d.off] != breakCode {
+		return false, nil
+	}
+
+	d.off++
+
+	return true, nil
+}
+
+// advance skips n bytes.
+func (d *decodeState) advance(n uint64) error {
+	if n > uint64(len(d.data)-d.off) {
+		return io.ErrUnexpectedEOF
+	}
+
+	d.off += int(n)
+
+	return nil
+}
+
+// skip skips the next item, checking that it is well-formed.
+func (d *decodeState) skip() error {
+	d.depth++
+	defer func() { d.depth-- }()
+
+	if d.depth > maxDepth {
+		return fmt.Errorf("cbor: exceeded max depth at offset %d", d.off)
+	}
+
+	major, info, arg, err := d.head()
+	if err != nil {
+		return err
+	}
+
+	switch major {
+	case majorBytes, majorText:
+		if info != infoIndefinite {
+			return d.advance(arg)
+		}
+
+		_, err := d.readChunks(major)
+
+		return err
+	case majorArray, majorMap:
+		items := 1
+		if major == majorMap {
+			items = 2
+		}
+
+		if info == infoIndefinite {
+			for {
+				done, err := d.atBreak()
+				if err != nil {
+					return err
+				}
+
+				if done {
+					return nil
+				}
+
+				for i := 0; i < items; i++ {
+					if err := d.skip(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		// Every item takes at least a byte, which bounds the count before looping over it
+		if arg > uint64(len(d.data)-d.off) {
+			return io.ErrUnexpectedEOF
+		}
+
+		for i := uint64(0); i < arg*uint64(items); i++ {
+			if err := d.skip(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case majorTag:
+		return d.skip()
+	case majorSimple:
+		if info == infoIndefinite {
+			return fmt.Errorf("cbor: unexpected break at offset %d", d.off-1)
+		}
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+// readChunks reads the definite length chunks of an indefinite length string up to its break.
+func (d *decodeState) readChunks(major byte) ([]byte, error) {
+	var s []byte
+
+	for {
+		done, err := d.atBreak()
+		if err != nil {
+			return nil, err
+		}
+
+		if done {
+			return s, nil
+		}
+
+		start := d.off
+
+		chunkMajor, info, arg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+
+		if chunkMajor != major || info == infoIndefinite {
+			return nil, fmt.Errorf("cbor: malformed chunk of %s at offset %d", majorNames[major], start)
+		}
+
+		if err := d.advance(arg); err != nil {
+			return nil, err
+		}
+
+		s = append(s, d.data[d.off-int(arg):d.off]...)
+	}
+}
+
+// readString returns the content of the string whose head was just read.
+func (d *decodeState) readString(major, info byte, arg uint64) ([]byte, error) {
+	if info == infoIndefinite {
+		return d.readChunks(major)
+	}
+
+	if err := d.advance(arg); err != nil {
+		return nil, err
+	}
+
+	return d.data[d.off-int(arg) : d.off], nil
+}
+
+// peek returns the next byte, which data checked by checkItem always has while decoding.
+func (d *decodeState) peek() byte {
+	return d.data[d.off]
+}
+
+func (d *decodeState) decode(v reflect.Value) error {
+	if b := d.peek(); b == simpleNull || b == simpleUndefined {
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			d.off++
+			v.Set(reflect.Zero(v.Type()))
+
+			return nil
+		}
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return d.decode(v.Elem())
+	}
+
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(unmarshalerType) {
+		start := d.off
+		if err := d.skip(); err != nil {
+			return err
+		}
+
+		data := append([]byte(nil), d.data[start:d.off]...)
+
+		return v.Addr().Interface().(Unmarshaler).UnmarshalCBOR(data)
+	}
+
+	if b := d.peek(); b == simpleNull || b == simpleUndefined {
+		// Like encoding/json, null leaves other values unchanged
+		d.off++
+
+		return nil
+	}
+
+	if v.Kind() == reflect.Interface {
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("cbor: cannot unmarshal into non-empty interface %v", v.Type())
+		}
+
+		value, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+
+		if value == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(value))
+		}
+
+		return nil
+	}
+
+	start := d.off
+
+	major, info, arg, err := d.head()
+	if err != nil {
+		return err
+	}
+
+	if major == majorTag {
+		return d.decode(v)
+	}
+
+	if major == majorText && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		text, err := d.readString(major, info, arg)
+		if err != nil {
+			return err
+		}
+
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(text)
+	}
+
+	switch major {
+	case majorUint:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if arg > math.MaxInt64 || v.OverflowInt(int64(arg)) {
+				return d.overflowError(arg, false, v, start)
+			}
+
+			v.SetInt(int64(arg))
+
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if v.OverflowUint(arg) {
+				return d.overflowError(arg, false, v, start)
+			}
+
+			v.SetUint(arg)
+
+			return nil
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(arg))
+
+			return nil
+		}
+	case majorNegInt:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if arg > math.MaxInt64 || v.OverflowInt(-1-int64(arg)) {
+				return d.overflowError(arg, true, v, start)
+			}
+
+			v.SetInt(-1 - int64(arg))
+
+			return nil
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(-1 - float64(arg))
+
+			return nil
+		}
+	case majorBytes:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.readString(major, info, arg)
+			if err != nil {
+				return err
+			}
+
+			v.SetBytes(append([]byte{}, b...))
+
+			return nil
+		}
+	case majorText:
+		if v.Kind() == reflect.String {
+			s, err := d.readString(major, info, arg)
+			if err != nil {
+				return err
+			}
+
+			v.SetString(string(s))
+
+			return nil
+		}
+	case majorArray:
+		switch v.Kind() {
+		case reflect.Slice:
+			return d.decodeSlice(v, info, arg)
+		case reflect.Array:
+			return d.decodeArray(v, info, arg)
+		}
+	case majorMap:
+		switch v.Kind() {
+		case reflect.Map:
+			return d.decodeMap(v, info, arg)
+		case reflect.Struct:
+			return d.decodeStruct(v, info, arg)
+		}
+	case majorSimple:
+		switch {
+		case v.Kind() == reflect.Bool && (info == simpleFalse&0x1f || info == simpleTrue&0x1f):
+			v.SetBool(info == simpleTrue&0x1f)
+
+			return nil
+		case (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) && info >= floatHalf&0x1f && info <= floatDouble&0x1f:
+			f := decodeFloat(info, arg)
+			if v.OverflowFloat(f) {
+				return fmt.Errorf("cbor: value %v at offset %d overflows %v", f, start, v.Type())
+			}
+
+			v.SetFloat(f)
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cbor: cannot unmarshal %s at offset %d into Go value of type %v", majorNames[major], start, v.Type())
+}
+
+func (d *decodeState) overflowError(arg uint64, negative bool, v reflect.Value, start int) error {
+	value := fmt.Sprint(arg)
+	if negative {
+		value = "-1-" + value
+	}
+
+	return fmt.Errorf("cbor: value %s at offset %d overflows %v", value, start, v.Type())
+}
+
+// decodeFloat returns the value of a half, single or double precision float with the bits arg.
+func decodeFloat(info byte, arg uint64) float64 {
+	switch info {
+	case floatHalf & 0x1f:
+		return float16ToFloat64(uint16(arg))
+	case floatSingle & 0x1f:
+		return float64(math.Float32frombits(uint32(arg)))
+	default:
+		return math.Float64frombits(arg)
+	}
+}
+
+func float16ToFloat64(h uint16) float64 {
+	exp, frac := int(h>>10&0x1f), float64(h&0x3ff)
+
+	var f float64
+
+	switch exp {
+	case 0:
+		f = math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(frac+0x400, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		f = -f
+	}
+
+	return f
+}
+
+// forEach calls fn for each element of the array or map whose head was just read.
+func (d *decodeState) forEach(info byte, arg uint64, fn func(i int) error) error {
+	for i := 0; info == infoIndefinite || uint64(i) < arg; i++ {
+		if info == infoIndefinite {
+			if done, err := d.atBreak(); err != nil || done {
+				return err
+			}
+		}
+
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *decodeState) decodeSlice(v reflect.Value, info byte, arg uint64) error {
+	v.Set(reflect.MakeSlice(v.Type(), int(arg), int(arg)))
+
+	return d.forEach(info, arg, func(i int) error {
+		if i >= v.Len() {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		}
+
+		return d.decode(v.Index(i))
+	})
+}
+
+func (d *decodeState) decodeArray(v reflect.Value, info byte, arg uint64) error {
+	n := 0
+
+	err := d.forEach(info, arg, func(i int) error {
+		n++
+
+		if i >= v.Len() {
+			return d.skip()
+		}
+
+		return d.decode(v.Index(i))
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := n; i < v.Len(); i++ {
+		v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+	}
+
+	return nil
+}
+
+func (d *decodeState) decodeMap(v reflect.Value, info byte, arg uint64) error {
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	keyType, elemType := v.Type().Key(), v.Type().Elem()
+
+	return d.forEach(info, arg, func(int) error {
+		key := reflect.New(keyType).Elem()
+		if err := d.decode(key); err != nil {
+			return err
+		}
+
+		elem := reflect.New(elemType).Elem()
+		if err := d.decode(elem); err != nil {
+			return err
+		}
+
+		v.SetMapIndex(key, elem)
+
+		return nil
+	})
+}
+
+func (d *decodeState) decodeStruct(v reflect.Value, info byte, arg uint64) error {
+	fields := typeFields(v.Type())
+
+	return d.forEach(info, arg, func(int) error {
+		start := d.off
+
+		var name string
+		if err := d.decode(reflect.ValueOf(&name).Elem()); err != nil {
+			// Keys other than text strings cannot name fields
+			d.off = start
+			if err := d.skip(); err != nil {
+				return err
+			}
+
+			name = ""
+		}
+
+		f, ok := lookupField(fields, name)
+		if !ok {
+			if d.strict {
+				return fmt.Errorf("cbor: unknown field %q at offset %d for %v", name, start, v.Type())
+			}
+
+			return d.skip()
+		}
+
+		fv, ok := fieldByIndex(v, f.index, true)
+		if !ok {
+			return fmt.Errorf("cbor: cannot set embedded pointer to unexported struct for field %q of %v", name, v.Type())
+		}
+
+		return d.decode(fv)
+	})
+}
+
+// lookupField returns the field of the name, preferring an exact match to a case-insensitive one like encoding/json.
+func lookupField(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+
+	return field{}, false
+}
+
+// decodeAny decodes the next item into the Go value of an empty interface.
+func (d *decodeState) decodeAny() (any, error) {
+	start := d.off
+
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+
+		return int64(arg), nil
+	case majorNegInt:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("cbor: value -1-%d at offset %d overflows int64", arg, start)
+		}
+
+		return -1 - int64(arg), nil
+	case majorBytes:
+		b, err := d.readString(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte{}, b...), nil
+	case majorText:
+		s, err := d.readString(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(s), nil
+	case majorArray:
+		a := []any{}
+
+		err := d.forEach(info, arg, func(int) error {
+			elem, err := d.decodeAny()
+			a = append(a, elem)
+
+			return err
+		})
+
+		return a, err
+	case majorMap:
+		m := map[string]any{}
+
+		err := d.forEach(info, arg, func(int) error {
+			keyStart := d.off
+
+			key, err := d.decodeAny()
+			if err != nil {
+				return err
+			}
+
+			name, ok := key.(string)
+			if !ok {
+				return fmt.Errorf("cbor: cannot unmarshal map key of type %T at offset %d into Go value of type map[string]any", key, keyStart)
+			}
+
+			m[name], err = d.decodeAny()
+
+			return err
+		})
+
+		return m, err
+	case majorTag:
+		return d.decodeAny()
+	default:
+		switch {
+		case info == simpleFalse&0x1f || info == simpleTrue&0x1f:
+			return info == simpleTrue&0x1f, nil
+		case info == simpleNull&0x1f || info == simpleUndefined&0x1f:
+			return nil, nil
+		case info >= floatHalf&0x1f && info <= floatDouble&0x1f:
+			return decodeFloat(info, arg), nil
+		default:
+			return nil, fmt.Errorf("cbor: cannot unmarshal simple value %d at offset %d into Go value of type any", arg, start)
+		}
+	}
+}
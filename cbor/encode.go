@@ -0,0 +1,454 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Major types of the initial byte of an item.
+const (
+	majorUint byte = iota
+	majorNegInt
+	majorBytes
+	majorText
+	majorArray
+	majorMap
+	majorTag
+	majorSimple
+)
+
+// Initial bytes of the simple values and floats.
+const (
+	simpleFalse     byte = 0xf4
+	simpleTrue      byte = 0xf5
+	simpleNull      byte = 0xf6
+	simpleUndefined byte = 0xf7
+	floatHalf       byte = 0xf9
+	floatSingle     byte = 0xfa
+	floatDouble     byte = 0xfb
+	breakCode       byte = 0xff
+)
+
+// infoIndefinite is the additional information of items with an indefinite length.
+const infoIndefinite = 31
+
+// maxDepth limits the nesting of encoded and decoded items, which also stops encoding cyclic values.
+const maxDepth = 10000
+
+var (
+	marshalerType     = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type encodeState struct {
+	buf   []byte
+	depth int
+}
+
+// appendHead appends the initial byte of an item of the major type with its argument in the shortest form.
+func appendHead(buf []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(buf, major<<5|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(buf, major<<5|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major<<5|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major<<5|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major<<5|27), arg)
+	}
+}
+
+// appendText appends s as a text string.
+func appendText(buf []byte, s string) []byte {
+	return append(appendHead(buf, majorText, uint64(len(s))), s...)
+}
+
+func (e *encodeState) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, simpleNull)
+
+		return nil
+	}
+
+	e.depth++
+	defer func() { e.depth-- }()
+
+	if e.depth > maxDepth {
+		return fmt.Errorf("cbor: exceeded max depth encoding %v, the value may be cyclic", v.Type())
+	}
+
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		e.buf = append(e.buf, simpleNull)
+
+		return nil
+	}
+
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(marshalerType) {
+		v = v.Addr()
+	}
+
+	if v.Type().Implements(marshalerType) {
+		data, err := v.Interface().(Marshaler).MarshalCBOR()
+		if err != nil {
+			return fmt.Errorf("cbor: error calling MarshalCBOR for type %v: %w", v.Type(), err)
+		}
+
+		if err := checkItem(data); err != nil {
+			return fmt.Errorf("cbor: invalid output of MarshalCBOR for type %v: %w", v.Type(), err)
+		}
+
+		e.buf = append(e.buf, data...)
+
+		return nil
+	}
+
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(textMarshalerType) {
+		v = v.Addr()
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return fmt.Errorf("cbor: error calling MarshalText for type %v: %w", v.Type(), err)
+		}
+
+		e.buf = append(appendHead(e.buf, majorText, uint64(len(text))), text...)
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, simpleTrue)
+		} else {
+			e.buf = append(e.buf, simpleFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int(); n >= 0 {
+			e.buf = appendHead(e.buf, majorUint, uint64(n))
+		} else {
+			e.buf = appendHead(e.buf, majorNegInt, uint64(-1-n))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.buf = appendHead(e.buf, majorUint, v.Uint())
+	case reflect.Float32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, floatSingle), math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, floatDouble), math.Float64bits(v.Float()))
+	case reflect.String:
+		e.buf = appendText(e.buf, v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, simpleNull)
+
+			return nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.buf = append(appendHead(e.buf, majorBytes, uint64(v.Len())), v.Bytes()...)
+
+			return nil
+		}
+
+		return e.encodeArray(v)
+	case reflect.Array:
+		return e.encodeArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, simpleNull)
+
+			return nil
+		}
+
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	case reflect.Pointer, reflect.Interface:
+		return e.encode(v.Elem())
+	default:
+		return fmt.Errorf("cbor: unsupported type %v", v.Type())
+	}
+
+	return nil
+}
+
+func (e *encodeState) encodeArray(v reflect.Value) error {
+	e.buf = appendHead(e.buf, majorArray, uint64(v.Len()))
+
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeMap encodes the entries of v sorted by their encoded keys, so that the encoding is deterministic.
+func (e *encodeState) encodeMap(v reflect.Value) error {
+	type entry struct {
+		key   []byte
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, v.Len())
+
+	for iter := v.MapRange(); iter.Next(); {
+		key := encodeState{depth: e.depth}
+
+		switch k := iter.Key(); {
+		case k.Kind() == reflect.String, k.Type().Implements(textMarshalerType),
+			k.Kind() >= reflect.Int && k.Kind() <= reflect.Uintptr:
+			if err := key.encode(k); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("cbor: unsupported map key type %v", k.Type())
+		}
+
+		entries = append(entries, entry{key: key.buf, value: iter.Value()})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	e.buf = appendHead(e.buf, majorMap, uint64(len(entries)))
+
+	for _, entry := range entries {
+		e.buf = append(e.buf, entry.key...)
+
+		if err := e.encode(entry.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *encodeState) encodeStruct(v reflect.Value) error {
+	fields := typeFields(v.Type())
+
+	values := make([]reflect.Value, len(fields))
+	count := 0
+
+	for i, f := range fields {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok || f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+
+		values[i] = fv
+		count++
+	}
+
+	e.buf = appendHead(e.buf, majorMap, uint64(count))
+
+	for i, f := range fields {
+		if !values[i].IsValid() {
+			continue
+		}
+
+		e.buf = appendText(e.buf, f.name)
+
+		if err := e.encode(values[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+// field is a struct field as it is encoded, possibly promoted from embedded structs.
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	tagged    bool
+	depth     int
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// typeFields returns the encoded fields of the struct type t in order. Like encoding/json, a name
+// promoted from embedded structs is kept only for the shallowest field, preferring tagged ones,
+// and dropped if that is ambiguous.
+func typeFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+
+	var candidates []field
+
+	visited := make(map[reflect.Type]bool)
+
+	var walk func(t reflect.Type, index []int, depth int)
+	walk = func(t reflect.Type, index []int, depth int) {
+		// Guard against embedding cycles through pointers
+		if visited[t] {
+			return
+		}
+
+		visited[t] = true
+		defer delete(visited, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+
+			name, omitEmpty, skip := parseTag(sf)
+			if skip {
+				continue
+			}
+
+			fieldIndex := append(append([]int(nil), index...), i)
+
+			if sf.Anonymous {
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				if name == "" && ft.Kind() == reflect.Struct {
+					walk(ft, fieldIndex, depth+1)
+
+					continue
+				}
+			}
+
+			if !sf.IsExported() {
+				continue
+			}
+
+			tagged := name != ""
+			if !tagged {
+				name = sf.Name
+			}
+
+			candidates = append(candidates, field{name: name, index: fieldIndex, omitEmpty: omitEmpty, tagged: tagged, depth: depth})
+		}
+	}
+
+	walk(t, nil, 0)
+
+	byName := make(map[string][]field)
+	for _, f := range candidates {
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	fields := make([]field, 0, len(candidates))
+
+	for _, f := range candidates {
+		if dominant, ok := dominantField(byName[f.name]); ok && dominant.depth == f.depth && dominant.tagged == f.tagged {
+			fields = append(fields, f)
+		}
+	}
+
+	actual, _ := fieldCache.LoadOrStore(t, fields)
+
+	return actual.([]field)
+}
+
+// dominantField returns the field that wins among the fields of the same name, if there is one.
+func dominantField(fields []field) (field, bool) {
+	depth := fields[0].depth
+	for _, f := range fields {
+		if f.depth < depth {
+			depth = f.depth
+		}
+	}
+
+	var shallowest, tagged []field
+
+	for _, f := range fields {
+		if f.depth != depth {
+			continue
+		}
+
+		shallowest = append(shallowest, f)
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	default:
+		return field{}, false
+	}
+}
+
+// parseTag returns the name and options of the field from its cbor tag, or else its json tag.
+func parseTag(sf reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag, ok := sf.Tag.Lookup("cbor")
+	if !ok {
+		tag = sf.Tag.Get("json")
+	}
+
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+
+	for opts != "" {
+		var opt string
+
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty, false
+}
+
+// fieldByIndex returns the field of the struct v at index, following embedded pointers.
+// Nil embedded pointers are allocated if alloc is set and possible, otherwise the field is reported missing.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
@@ -0,0 +1,163 @@
+package cbor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// pair is an encoded key and value of a map.
+type pair struct {
+	key, value []byte
+}
+
+// mapPairs splits the encoded map data into its pairs.
+func mapPairs(data []byte) ([]pair, error) {
+	if err := checkItem(data); err != nil {
+		return nil, err
+	}
+
+	d := decodeState{data: data}
+
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	if major != majorMap {
+		return nil, fmt.Errorf("cbor: expected map, got %s", majorNames[major])
+	}
+
+	var pairs []pair
+
+	err = d.forEach(info, arg, func(int) error {
+		keyStart := d.off
+		if err := d.skip(); err != nil {
+			return err
+		}
+
+		valueStart := d.off
+		if err := d.skip(); err != nil {
+			return err
+		}
+
+		pairs = append(pairs, pair{key: data[keyStart:valueStart], value: data[valueStart:d.off]})
+
+		return nil
+	})
+
+	return pairs, err
+}
+
+// appendMap encodes the pairs as a map of definite length.
+func appendMap(pairs []pair) []byte {
+	buf := appendHead(nil, majorMap, uint64(len(pairs)))
+	for _, p := range pairs {
+		buf = append(append(buf, p.key...), p.value...)
+	}
+
+	return buf
+}
+
+// isKey reports whether the encoded key is the text string name.
+func isKey(key []byte, name string) bool {
+	d := decodeState{data: key}
+
+	var s string
+	if err := d.decode(reflect.ValueOf(&s).Elem()); err != nil {
+		return false
+	}
+
+	return s == name
+}
+
+// InsertPath sets the key at path in the encoded map data to the encoded value, merging it into nested maps
+// and creating missing ones. The last key of the path is moved to the front of its map, so that decoders reading
+// the map in order see it first.
+func InsertPath(data []byte, path []string, value []byte) ([]byte, error) {
+	pairs, err := mapPairs(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key := appendText(nil, path[0])
+
+	for i, p := range pairs {
+		if !isKey(p.key, path[0]) {
+			continue
+		}
+
+		if len(path) == 1 {
+			pairs = append(pairs[:i:i], pairs[i+1:]...)
+
+			break
+		}
+
+		member := p.value
+		if IsNull(member) {
+			member = appendMap(nil)
+		}
+
+		nested, err := InsertPath(member, path[1:], value)
+		if err != nil {
+			return nil, fmt.Errorf("at %s: %w", path[0], err)
+		}
+
+		pairs[i].value = nested
+
+		return appendMap(pairs), nil
+	}
+
+	// The key is missing, so create it
+	if len(path) > 1 {
+		value, err = InsertPath(appendMap(nil), path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return appendMap(append([]pair{{key: key, value: value}}, pairs...)), nil
+}
+
+// ExtractPath returns the encoded value of the key at path in the encoded map data, following nested maps,
+// and data without the key, dropping nested maps left empty. If the path is missing, value is nil and
+// data is returned as is.
+func ExtractPath(data []byte, path []string) (value RawMessage, rest []byte, err error) {
+	pairs, err := mapPairs(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, p := range pairs {
+		if !isKey(p.key, path[0]) {
+			continue
+		}
+
+		if len(path) == 1 {
+			return RawMessage(p.value), appendMap(append(pairs[:i:i], pairs[i+1:]...)), nil
+		}
+
+		if IsNull(p.value) {
+			return nil, data, nil
+		}
+
+		value, nested, err := ExtractPath(p.value, path[1:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("at %s: %w", path[0], err)
+		}
+
+		if value == nil {
+			return nil, data, nil
+		}
+
+		if len(nested) == 1 {
+			// The nested map is left empty
+			pairs = append(pairs[:i:i], pairs[i+1:]...)
+		} else {
+			pairs[i].value = nested
+		}
+
+		return value, appendMap(pairs), nil
+	}
+
+	return nil, data, nil
+}
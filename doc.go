@@ -41,16 +41,55 @@ Configuration options:
 		- typeName         Name of the polymorphic structure
 	  	- interface        Name of the interface all subtypes implement
 	  	- package          Package name for the generated file
-	  	- discriminator    Override default discriminator field name (optional)
+	  	- discriminator    Override default discriminator field name, nested as meta.type or /meta/type (optional)
+	  	- discriminatorType Go type of discriminator values: string (default), int or a named type (optional)
+	  	- discriminatorField Go field of every subtype holding the discriminator, marshaled by the subtype itself (optional)
+	  	- versionField     JSON field name of the schema version of versioned subtypes (optional)
+	  	- discriminatorMatch Matching of discriminator values on unmarshal: exact (default), case-insensitive or normalized (optional)
 	  	- directory        Output directory path relative to config file (optional)
 	  	- filename         Output filename (defaults to <type>_polygen.go)
 	  	- strict           Override strict mode for this type (optional, does not apply to jsonv2)
 	  	- defaultSubtype   Default subtype to unmarshal into when the discriminator field is missing (optional)
+	  	- marshalNil       Marshaling of a nil or null subtype: null (default) or error (optional)
+	  	- unmarshalNull    Unmarshaling of null: zero (default), default to decode the default subtype, or error (optional)
+	  	- validate         Call the Validate() error method of subtypes implementing it after unmarshaling (optional)
+	  	- isZero           Generate an IsZero method for the omitzero tag option (optional)
+	  	- xml              Generate MarshalXML and UnmarshalXML discriminating by an attribute or the element name (optional, attribute, element)
+	  	- yaml             Generate MarshalYAML and UnmarshalYAML for gopkg.in/yaml.v3 (optional)
+	  	- gob              Generate GobEncode, GobDecode and a Register<Type>Gob function (optional)
+	  	- sql              Generate Scan and Value methods storing the type as JSON in a database column (optional)
+	  	- cbor             Generate MarshalCBOR and UnmarshalCBOR using the polygen/cbor runtime (optional)
+	  	- slog             Generate a slog.LogValuer LogValue method and a String method naming the subtype (optional, go1.21)
+	  	- equal            Generate an Equal method treating a subtype and a pointer to it as the same (optional)
+	  	- clone            Generate a Clone method deep copying the subtype (optional)
+	  	- containers       Generate <Type>List and <Type>Map types with Filter<Subtype> and CountBy<Subtype> methods (optional)
+	  	- arrayDecoder     Generate a <Type>ArrayDecoder reading JSON arrays one element at a time, with an iter.Seq2 for go1.23 (optional)
+	  	- proto            Generate a .proto oneof message and conversions to and from it, with package, goPackage and filename (optional)
 	  	- buildTag         Override build tag constraint for this type (optional)
 	  	- jsonVersion      JSON library version to target for this type (optional, v1, v2, both)
+	  	- templates        User-supplied templates (optional), each with:
+	    	- path       Template file path relative to config file
+	    	- replace    Built-in template to replace (v1, v2, xml, yaml, gob, sql, cbor, slog, equal, clone, containers, decoder or decoder-iter)
+	    	- filename   Additional output file next to the generated code (if not replacing)
 	  	- subtypes         Map of Go types to their configurations:
 	    	- name       JSON type name (optional, defaults to subtype in kebab-case)
 			- pointer    Use pointer for this type (optional, default: false)
+			- deprecated Report each unmarshaling to the generated <Type>DeprecatedHook (optional)
+			- version    Current schema version of the subtype (optional)
+			- upgrade    Function upgrading the subtype members of older versions: func([]byte, int) ([]byte, error) (optional)
+			- protoNumber Field number of the subtype in the protobuf oneof, instead of the locked one (optional)
+
+Command-line flags:
+
+	-config    Path to the configuration file (default: ".polygen.json")
+	-prune     Remove generated files that no configured type maps to, skipping nested directories with their own config
+	-dry-run   List files that would be created, changed, left unchanged or removed without writing them
+	-stdout    Print the generated code for the given type to standard output instead of writing it
+	-watch     Poll the config file, templates and packages of configured types and regenerate affected types on changes
+	-proto     Generate only the .proto files, conversions and field number lock files of the types with a proto config
+
+The generator itself lives in the github.com/ykalchevskiy/polygen/gen package, which can be imported
+to load, validate, render and write configurations programmatically.
 
 Example:
 
@@ -179,7 +179,7 @@ func (v *Item) UnmarshalJSON(data []byte) error {
 			value = vv.TextItem
 		}
 	default:
-		return fmt.Errorf("polygen: unknown subtype for Item: %s", typeName)
+		return fmt.Errorf("polygen: unknown subtype for Item: %v", typeName)
 	}
 
 	*v = Item{
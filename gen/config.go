@@ -0,0 +1,589 @@
+package gen
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const defaultDiscriminator = "type"
+
+const (
+	JSONVersionV1   = "v1"
+	JSONVersionV2   = "v2"
+	JSONVersionBoth = "both"
+)
+
+const (
+	DiscriminatorTypeString = "string"
+	DiscriminatorTypeInt    = "int"
+)
+
+const (
+	MarshalNilNull  = "null"
+	MarshalNilError = "error"
+)
+
+const (
+	UnmarshalNullZero    = "zero"
+	UnmarshalNullDefault = "default"
+	UnmarshalNullError   = "error"
+)
+
+const (
+	XMLAttribute = "attribute"
+	XMLElement   = "element"
+)
+
+// Names of the built-in templates besides the JSON ones for the replace option of user-supplied templates.
+const (
+	TemplateXML         = "xml"
+	TemplateYAML        = "yaml"
+	TemplateGob         = "gob"
+	TemplateSQL         = "sql"
+	TemplateCBOR        = "cbor"
+	TemplateSlog        = "slog"
+	TemplateEqual       = "equal"
+	TemplateClone       = "clone"
+	TemplateContainers  = "containers"
+	TemplateDecoder     = "decoder"
+	TemplateDecoderIter = "decoder-iter"
+)
+
+const (
+	DiscriminatorMatchExact           = "exact"
+	DiscriminatorMatchCaseInsensitive = "case-insensitive"
+	DiscriminatorMatchNormalized      = "normalized"
+)
+
+// Config represents the internal configuration used by the generator.
+type Config struct {
+	Type               string
+	Interface          string
+	Package            string
+	Types              []TypeMapping
+	Discriminator      string
+	DiscriminatorType  string
+	DiscriminatorMatch string
+	DiscriminatorField string
+	VersionField       string
+	Validate           bool
+	Strict             bool
+	DefaultSubtypeName string
+	MarshalNil         string
+	UnmarshalNull      string
+	IsZero             bool
+	XML                string
+	YAML               bool
+	Gob                bool
+	SQL                bool
+	CBOR               bool
+	Slog               bool
+	Equal              bool
+	Clone              bool
+	Containers         bool
+	ArrayDecoder       bool
+	BuildTag           string
+	JSONVersion        string
+}
+
+// DiscriminatorLiteral returns the Go expression of the discriminator value name.
+// Names are quoted for string discriminators and used as is otherwise (integers or constants of a named type).
+func (c *Config) DiscriminatorLiteral(name string) string {
+	if c.DiscriminatorType == DiscriminatorTypeString {
+		return strconv.Quote(name)
+	}
+
+	return name
+}
+
+// DiscriminatorZero returns the Go expression of the zero discriminator value, which means a missing discriminator.
+func (c *Config) DiscriminatorZero() string {
+	switch c.DiscriminatorType {
+	case DiscriminatorTypeString:
+		return `""`
+	case DiscriminatorTypeInt:
+		return "0"
+	default:
+		return "*new(" + c.DiscriminatorType + ")"
+	}
+}
+
+// DiscriminatorMatchKey returns the key a discriminator value is matched by on unmarshal.
+// Case-insensitive matching lower-cases the value, normalized matching converts it to snake_case,
+// reading '-' as '_', so that kebab-case, snake_case and PascalCase spellings of a name are equivalent.
+func (c *Config) DiscriminatorMatchKey(name string) string {
+	switch c.DiscriminatorMatch {
+	case DiscriminatorMatchCaseInsensitive:
+		return strings.ToLower(name)
+	case DiscriminatorMatchNormalized:
+		return strings.ReplaceAll(toSnakeCase(name), "-", "_")
+	default:
+		return name
+	}
+}
+
+// DiscriminatorPath returns the keys leading to the discriminator, which may be nested
+// using a dotted path (meta.type) or a JSON pointer (/meta/type).
+func (c *Config) DiscriminatorPath() []string {
+	return parseDiscriminatorPath(c.Discriminator)
+}
+
+// IsNestedDiscriminator reports whether the discriminator is inside a nested object.
+func (c *Config) IsNestedDiscriminator() bool {
+	return len(c.DiscriminatorPath()) > 1
+}
+
+// DiscriminatorPathLiteral returns the Go expression of the discriminator path as a string slice.
+func (c *Config) DiscriminatorPathLiteral() string {
+	path := c.DiscriminatorPath()
+
+	quoted := make([]string, len(path))
+	for i, key := range path {
+		quoted[i] = strconv.Quote(key)
+	}
+
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+// HasDeprecated reports whether any subtype is deprecated.
+func (c *Config) HasDeprecated() bool {
+	for _, mapping := range c.Types {
+		if mapping.Deprecated {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasVersions reports whether any subtype has a schema version.
+func (c *Config) HasVersions() bool {
+	for _, mapping := range c.Types {
+		if mapping.Version > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasUpgrades reports whether any subtype has an upgrade function.
+func (c *Config) HasUpgrades() bool {
+	for _, mapping := range c.Types {
+		if mapping.Upgrade != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GobName returns the stable name a subtype with the given type name is registered with encoding/gob under.
+func (c *Config) GobName(typeName string) string {
+	return c.Package + "." + typeName
+}
+
+// TypeMapping represents a mapping between a concrete type and its JSON type name.
+type TypeMapping struct {
+	SubType     string
+	TypeName    string
+	IsPointer   bool
+	Deprecated  bool
+	Version     int
+	Upgrade     string
+	ProtoNumber int
+}
+
+// FileConfig represents the configuration file structure.
+type FileConfig struct {
+	// Dir is the directory output paths are relative to, set by LoadConfig to the directory of the config file
+	Dir string `json:"-"`
+	// Types is a list of type configurations to generate
+	Types []FileTypeConfig `json:"types"`
+	// StrictByDefault determines if strict mode should be enabled by default for all types (does not apply to jsonv2)
+	StrictByDefault bool `json:"strictByDefault,omitempty"`
+	// PointerByDefault determines if pointer mode should be enabled by default for all subtypes
+	PointerByDefault bool `json:"pointerByDefault,omitempty"`
+	// DefaultDiscriminator is the default JSON field name to distinguish types
+	DefaultDiscriminator string `json:"defaultDiscriminator,omitempty"`
+	// DefaultBuildTag is the build constraint for generated code
+	DefaultBuildTag string `json:"defaultBuildTag,omitempty"`
+	// JSONVersionByDefault determines the json version generation enabled by default (v1, v2, both)
+	JSONVersionByDefault string `json:"jsonVersionByDefault,omitempty"`
+}
+
+// FileTypeConfig represents configuration for a single polymorphic type.
+type FileTypeConfig struct {
+	// Type is the name of the polymorphic structure to generate
+	Type string `json:"type"`
+	// Interface is the name of the interface all subtypes implement
+	Interface string `json:"interface"`
+	// Package is the package name for the generated file
+	Package string `json:"package"`
+	// Subtypes maps Go types to their configurations
+	Subtypes map[string]FileSubtypeConfig `json:"subtypes"`
+	// Directory is the output directory path, relative to the config file
+	Directory string `json:"directory,omitempty"`
+	// Filename is the output filename, defaults to <type>_polygen.go in snake_case
+	Filename string `json:"filename,omitempty"`
+	// Discriminator is the JSON field name to distinguish types
+	Discriminator string `json:"discriminator,omitempty"`
+	// DiscriminatorType is the Go type of the discriminator values: string (default), int or a named type of the package
+	DiscriminatorType string `json:"discriminatorType,omitempty"`
+	// DiscriminatorField is the Go field of every subtype holding the discriminator, which the subtype then marshals itself
+	DiscriminatorField string `json:"discriminatorField,omitempty"`
+	// VersionField is the JSON field name holding the schema version of versioned subtypes
+	VersionField string `json:"versionField,omitempty"`
+	// DiscriminatorMatch is the policy matching discriminator values on unmarshal: exact (default), case-insensitive or normalized
+	DiscriminatorMatch string `json:"discriminatorMatch,omitempty"`
+	// Strict enables strict JSON unmarshaling for this type (does not apply to jsonv2)
+	Strict *bool `json:"strict,omitempty"`
+	// DefaultSubtype is the default subtype to use when the discriminator is missing
+	DefaultSubtype string `json:"defaultSubtype,omitempty"`
+	// MarshalNil is the policy for marshaling a nil or null subtype: null (default) or error
+	MarshalNil string `json:"marshalNil,omitempty"`
+	// UnmarshalNull is the policy for unmarshaling null: zero (default) to clear the value, default to decode the default subtype or error
+	UnmarshalNull string `json:"unmarshalNull,omitempty"`
+	// Validate calls the Validate() error method of subtypes implementing it after unmarshaling
+	Validate bool `json:"validate,omitempty"`
+	// IsZero generates an IsZero method, reporting a nil or nil pointer subtype, for use with the omitzero tag option
+	IsZero bool `json:"isZero,omitempty"`
+	// XML generates MarshalXML and UnmarshalXML methods discriminating subtypes by an attribute or the element name (attribute, element)
+	XML string `json:"xml,omitempty"`
+	// YAML generates MarshalYAML and UnmarshalYAML methods for gopkg.in/yaml.v3
+	YAML bool `json:"yaml,omitempty"`
+	// Gob generates GobEncode and GobDecode methods and a function registering the subtypes with encoding/gob
+	Gob bool `json:"gob,omitempty"`
+	// SQL generates sql.Scanner and driver.Valuer methods storing the type as JSON in a database column
+	SQL bool `json:"sql,omitempty"`
+	// CBOR generates MarshalCBOR and UnmarshalCBOR methods using the self-contained polygen/cbor runtime
+	CBOR bool `json:"cbor,omitempty"`
+	// Slog generates a LogValue method for log/slog and a String method, both naming the subtype
+	Slog bool `json:"slog,omitempty"`
+	// Equal generates an Equal method comparing the subtypes regardless of their value or pointer form
+	Equal bool `json:"equal,omitempty"`
+	// Clone generates a Clone method deep copying the subtype
+	Clone bool `json:"clone,omitempty"`
+	// Containers generates <Type>List and <Type>Map types with subtype filters and element-wise JSON decoding
+	Containers bool `json:"containers,omitempty"`
+	// ArrayDecoder generates a decoder of JSON arrays of the type reading one element at a time and an iter.Seq2 over it
+	ArrayDecoder bool `json:"arrayDecoder,omitempty"`
+	// Proto generates a .proto file with a message holding the subtypes in a oneof and functions converting to and from it
+	Proto *FileProtoConfig `json:"proto,omitempty"`
+	// BuildTag is the build constraint for this type
+	BuildTag string `json:"buildTag,omitempty"`
+	// JSONVersion enables generation of jsonv2 code for this type (v1, v2, both)
+	JSONVersion string `json:"jsonVersion,omitempty"`
+	// Templates lists user-supplied templates replacing the built-in ones or rendering additional files
+	Templates []FileTemplateConfig `json:"templates,omitempty"`
+}
+
+// FileTemplateConfig represents a user-supplied template for a type.
+type FileTemplateConfig struct {
+	// Path is the template file path, relative to the config file
+	Path string `json:"path"`
+	// Replace is the built-in template this one replaces (v1, v2, xml, yaml, gob, sql, cbor, slog, equal, clone, containers, decoder, decoder-iter); if empty, the template renders an additional file
+	Replace string `json:"replace,omitempty"`
+	// Filename is the name of the additional file, placed next to the generated code
+	Filename string `json:"filename,omitempty"`
+}
+
+// FileProtoConfig represents the protobuf message generated for a type.
+type FileProtoConfig struct {
+	// Package is the protobuf package of the messages
+	Package string `json:"package"`
+	// GoPackage is the import path of the Go code protoc-gen-go generates from the .proto file
+	GoPackage string `json:"goPackage"`
+	// Filename is the name of the .proto file placed next to the generated code, defaults to <filename> with the .proto extension
+	Filename string `json:"filename,omitempty"`
+}
+
+// FileSubtypeConfig represents configuration for a subtype.
+type FileSubtypeConfig struct {
+	// Name is the JSON type name, defaults to the subtype name in snake_case if not specified
+	Name *string `json:"name,omitempty"`
+	// Pointer indicates if this type should be used as a pointer
+	Pointer *bool `json:"pointer,omitempty"`
+	// Deprecated keeps decoding the subtype but reports each occurrence to the generated deprecation hook
+	Deprecated bool `json:"deprecated,omitempty"`
+	// Version is the current schema version of the subtype, written to the version field on marshal
+	Version int `json:"version,omitempty"`
+	// Upgrade is the function of the package converting the JSON of an older version to the current one
+	Upgrade string `json:"upgrade,omitempty"`
+	// ProtoNumber pins the field number of the subtype in the oneof of the protobuf message
+	ProtoNumber int `json:"protoNumber,omitempty"`
+}
+
+func convertFileConfigToConfig(typeConfig *FileTypeConfig, config *FileConfig) *Config {
+	cfg := &Config{
+		Type:               typeConfig.Type,
+		Interface:          typeConfig.Interface,
+		Package:            typeConfig.Package,
+		Discriminator:      typeConfig.Discriminator,
+		DiscriminatorType:  typeConfig.DiscriminatorType,
+		DiscriminatorMatch: typeConfig.DiscriminatorMatch,
+		DiscriminatorField: typeConfig.DiscriminatorField,
+		VersionField:       typeConfig.VersionField,
+		MarshalNil:         typeConfig.MarshalNil,
+		UnmarshalNull:      typeConfig.UnmarshalNull,
+		IsZero:             typeConfig.IsZero,
+		Validate:           typeConfig.Validate,
+		XML:                typeConfig.XML,
+		YAML:               typeConfig.YAML,
+		Gob:                typeConfig.Gob,
+		SQL:                typeConfig.SQL,
+		CBOR:               typeConfig.CBOR,
+		Slog:               typeConfig.Slog,
+		Equal:              typeConfig.Equal,
+		Clone:              typeConfig.Clone,
+		Containers:         typeConfig.Containers,
+		ArrayDecoder:       typeConfig.ArrayDecoder,
+		Strict:             config.StrictByDefault,
+		BuildTag:           config.DefaultBuildTag,
+		JSONVersion:        config.JSONVersionByDefault,
+	}
+
+	if cfg.Discriminator == "" {
+		cfg.Discriminator = config.DefaultDiscriminator
+	}
+
+	if cfg.Discriminator == "" {
+		cfg.Discriminator = defaultDiscriminator
+	}
+
+	// A JSON pointer to a top-level key is just the key
+	if path := parseDiscriminatorPath(cfg.Discriminator); len(path) == 1 {
+		cfg.Discriminator = path[0]
+	}
+
+	if cfg.DiscriminatorType == "" {
+		cfg.DiscriminatorType = DiscriminatorTypeString
+	}
+
+	if cfg.DiscriminatorMatch == "" {
+		cfg.DiscriminatorMatch = DiscriminatorMatchExact
+	}
+
+	if cfg.MarshalNil == "" {
+		cfg.MarshalNil = MarshalNilNull
+	}
+
+	if cfg.UnmarshalNull == "" {
+		cfg.UnmarshalNull = UnmarshalNullZero
+	}
+
+	if typeConfig.Strict != nil {
+		cfg.Strict = *typeConfig.Strict
+	}
+
+	if typeConfig.BuildTag != "" {
+		cfg.BuildTag = typeConfig.BuildTag
+	}
+
+	if typeConfig.JSONVersion != "" {
+		cfg.JSONVersion = typeConfig.JSONVersion
+	}
+
+	if cfg.JSONVersion == "" {
+		cfg.JSONVersion = JSONVersionV1
+	} else if cfg.JSONVersion != JSONVersionV1 && cfg.JSONVersion != JSONVersionV2 && cfg.JSONVersion != JSONVersionBoth {
+		cfg.JSONVersion = JSONVersionV1
+	}
+
+	var defaultSubtypeName string
+
+	for subType, subCfg := range typeConfig.Subtypes {
+		var typeName string
+		if subCfg.Name != nil {
+			typeName = *subCfg.Name
+		} else {
+			typeName = toKebabCase(subType)
+		}
+
+		if subType == typeConfig.DefaultSubtype {
+			defaultSubtypeName = typeName
+		}
+
+		var isPointer bool
+		if subCfg.Pointer != nil {
+			isPointer = *subCfg.Pointer
+		} else {
+			isPointer = config.PointerByDefault
+		}
+
+		cfg.Types = append(cfg.Types, TypeMapping{
+			SubType:     subType,
+			TypeName:    typeName,
+			IsPointer:   isPointer,
+			Deprecated:  subCfg.Deprecated,
+			Version:     subCfg.Version,
+			Upgrade:     subCfg.Upgrade,
+			ProtoNumber: subCfg.ProtoNumber,
+		})
+	}
+
+	cfg.DefaultSubtypeName = defaultSubtypeName
+
+	// Sort types by SubType for consistent output
+	sort.Slice(cfg.Types, func(i, j int) bool {
+		return cfg.Types[i].SubType < cfg.Types[j].SubType
+	})
+
+	return cfg
+}
+
+func getOutputPath(typeConfig *FileTypeConfig, configDir string) string {
+	var outputPath string
+	if typeConfig.Directory != "" {
+		outputPath = filepath.Join(configDir, typeConfig.Directory)
+	} else {
+		outputPath = configDir
+	}
+
+	if typeConfig.Filename != "" {
+		outputPath = filepath.Join(outputPath, typeConfig.Filename)
+	} else {
+		outputPath = filepath.Join(outputPath, toSnakeCase(typeConfig.Type)+"_polygen.go")
+	}
+
+	return outputPath
+}
+
+// parseDiscriminatorPath splits a dotted path or a JSON pointer into keys.
+func parseDiscriminatorPath(s string) []string {
+	if !strings.HasPrefix(s, "/") {
+		return strings.Split(s, ".")
+	}
+
+	keys := strings.Split(s[1:], "/")
+	for i, key := range keys {
+		keys[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+	}
+
+	return keys
+}
+
+// getOutputPathJSONV2 returns the path of the jsonv2 file generated next to outputPath when both versions are targeted.
+func getOutputPathJSONV2(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_jsonv2.go"
+}
+
+// getOutputPathXML returns the path of the XML file generated next to outputPath.
+func getOutputPathXML(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_xml.go"
+}
+
+// getOutputPathYAML returns the path of the YAML file generated next to outputPath.
+func getOutputPathYAML(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_yaml.go"
+}
+
+// getOutputPathGob returns the path of the gob file generated next to outputPath.
+func getOutputPathGob(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_gob.go"
+}
+
+// getOutputPathSQL returns the path of the database/sql file generated next to outputPath.
+func getOutputPathSQL(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_sql.go"
+}
+
+// getOutputPathProto returns the paths of the .proto file, of the Go conversion functions and of the lock file
+// with the field numbers of the messages generated next to outputPath.
+func getOutputPathProto(outputPath string, proto *FileProtoConfig) (protoPath, goPath, lockPath string) {
+	base := strings.TrimSuffix(outputPath, ".go")
+
+	protoPath = base + ".proto"
+	if proto.Filename != "" {
+		protoPath = filepath.Join(filepath.Dir(outputPath), proto.Filename)
+	}
+
+	return protoPath, base + "_proto.go", strings.TrimSuffix(protoPath, ".proto") + "_proto.lock.json"
+}
+
+// getOutputPathCBOR returns the path of the CBOR file generated next to outputPath.
+func getOutputPathCBOR(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_cbor.go"
+}
+
+// getOutputPathSlog returns the path of the log/slog file generated next to outputPath.
+func getOutputPathSlog(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_slog.go"
+}
+
+// getOutputPathEqual returns the path of the Equal file generated next to outputPath.
+func getOutputPathEqual(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_equal.go"
+}
+
+// getOutputPathClone returns the path of the Clone file generated next to outputPath.
+func getOutputPathClone(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_clone.go"
+}
+
+// getOutputPathContainers returns the path of the container types file generated next to outputPath.
+func getOutputPathContainers(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_containers.go"
+}
+
+// getOutputPathDecoder returns the path of the array decoder file generated next to outputPath.
+func getOutputPathDecoder(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_decoder.go"
+}
+
+// getOutputPathDecoderIter returns the path of the array decoder iterator file generated next to outputPath.
+func getOutputPathDecoderIter(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_decoder_iter.go"
+}
+
+// toKebabCase converts a string from PascalCase to kebab-case.
+func toKebabCase(s string) string {
+	return toCase(s, rune('-'))
+}
+
+// toSnakeCase converts a string from PascalCase to snake_case.
+func toSnakeCase(s string) string {
+	return toCase(s, rune('_'))
+}
+
+func toCase(s string, sep rune) string {
+	var result strings.Builder
+
+	runes := []rune(s)
+	length := len(runes)
+
+	for i := 0; i < length; i++ {
+		current := runes[i]
+
+		if i > 0 {
+			prev := runes[i-1]
+			next := rune(0)
+
+			if i+1 < length {
+				next = runes[i+1]
+			}
+
+			switch {
+			case unicode.IsLower(prev) && unicode.IsUpper(current):
+				// lower -> Upper (e.g., myTest -> my_test)
+				result.WriteRune(sep)
+			case unicode.IsUpper(prev) && unicode.IsUpper(current) && next != 0 && unicode.IsLower(next):
+				// (e.g., HTTPServer -> http_server)
+				result.WriteRune(sep)
+			case unicode.IsLetter(prev) && unicode.IsDigit(current):
+				// (e.g., test123 -> test_123)
+				result.WriteRune(sep)
+			case unicode.IsDigit(prev) && unicode.IsLetter(current):
+				// (e.g., version2Test -> version_2_test)
+				result.WriteRune(sep)
+			}
+		}
+
+		result.WriteRune(unicode.ToLower(current))
+	}
+
+	return result.String()
+}
@@ -1,4 +1,4 @@
-package main
+package gen
 
 import (
 	"reflect"
@@ -126,10 +126,14 @@ func Test_convertFileConfigToConfig(t *testing.T) {
 				},
 			},
 			want: &Config{
-				Type:          "Shape",
-				Interface:     "Shape",
-				Package:       "main",
-				Discriminator: "kind",
+				Type:               "Shape",
+				Interface:          "Shape",
+				Package:            "main",
+				Discriminator:      "kind",
+				DiscriminatorType:  "string",
+				DiscriminatorMatch: "exact",
+				MarshalNil:         "null",
+				UnmarshalNull:      "zero",
 				Types: []TypeMapping{
 					{SubType: "Circle", TypeName: "circle"},
 					{SubType: "Rectangle", TypeName: "rectangle"},
@@ -157,6 +161,10 @@ func Test_convertFileConfigToConfig(t *testing.T) {
 				Interface:          "Shape",
 				Package:            "main",
 				Discriminator:      "type",
+				DiscriminatorType:  "string",
+				DiscriminatorMatch: "exact",
+				MarshalNil:         "null",
+				UnmarshalNull:      "zero",
 				DefaultSubtypeName: "rectangle",
 				Types: []TypeMapping{
 					{SubType: "Circle", TypeName: "circle"},
@@ -182,10 +190,14 @@ func Test_convertFileConfigToConfig(t *testing.T) {
 				},
 			},
 			want: &Config{
-				Type:          "Shape",
-				Interface:     "Shape",
-				Package:       "main",
-				Discriminator: "type",
+				Type:               "Shape",
+				Interface:          "Shape",
+				Package:            "main",
+				Discriminator:      "type",
+				DiscriminatorType:  "string",
+				DiscriminatorMatch: "exact",
+				MarshalNil:         "null",
+				UnmarshalNull:      "zero",
 				Types: []TypeMapping{
 					{SubType: "Rectangle", TypeName: "rectangle"},
 				},
@@ -208,10 +220,14 @@ func Test_convertFileConfigToConfig(t *testing.T) {
 				},
 			},
 			want: &Config{
-				Type:          "Shape",
-				Interface:     "Shape",
-				Package:       "main",
-				Discriminator: "type",
+				Type:               "Shape",
+				Interface:          "Shape",
+				Package:            "main",
+				Discriminator:      "type",
+				DiscriminatorType:  "string",
+				DiscriminatorMatch: "exact",
+				MarshalNil:         "null",
+				UnmarshalNull:      "zero",
 				Types: []TypeMapping{
 					{SubType: "Rectangle", TypeName: "rectangle"},
 				},
@@ -0,0 +1,399 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// CheckFields type checks the packages of the configured types and reports subtype JSON fields,
+// including fields promoted from embedded structs, that collide with the discriminator, or for a
+// nested discriminator the field at its path through nested structs, as well as subtypes missing
+// the field configured to hold the discriminator or whose field has a type the discriminator type is
+// not assignable to, and names of a named discriminator type which are constants of the zero value,
+// read as a missing discriminator.
+// Subtypes which cannot be resolved are skipped. All collisions found are joined into the returned error.
+// Types whose package cannot be loaded or parsed, e.g. because a file is being edited, are not checked;
+// a warning for each such package is returned instead.
+func CheckFields(config *FileConfig) (warnings []string, err error) {
+	var errs []error
+
+	packages := make(map[string]*types.Package)
+
+	for i := range config.Types {
+		typeConfig := &config.Types[i]
+
+		cfg := convertFileConfigToConfig(typeConfig, config)
+
+		dir := filepath.Dir(getOutputPath(typeConfig, config.Dir))
+
+		pkg, ok := packages[dir]
+		if !ok {
+			var err error
+
+			pkg, err = loadPackage(dir)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("skipping field checks of '%s': %v", dir, err))
+			}
+
+			packages[dir] = pkg
+		}
+
+		// The package could not be loaded, which has been warned about once for its directory
+		if pkg == nil {
+			continue
+		}
+
+		errs = append(errs, checkDiscriminatorConstants(cfg, pkg)...)
+
+		discriminatorType := lookupDiscriminatorType(cfg, pkg)
+
+		for _, mapping := range cfg.Types {
+			obj, ok := pkg.Scope().Lookup(mapping.SubType).(*types.TypeName)
+			if !ok {
+				continue
+			}
+
+			s, ok := obj.Type().Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+
+			if cfg.IsNestedDiscriminator() {
+				if path, ok := jsonFieldAt(s, cfg.DiscriminatorPath()); ok {
+					errs = append(errs, fmt.Errorf("type '%s': field '%s' of subtype '%s' is at the JSON path '%s' of the discriminator",
+						typeConfig.Type, path, mapping.SubType, cfg.Discriminator))
+				}
+
+				continue
+			}
+
+			var backed bool
+
+			for _, field := range jsonFields(s) {
+				if field.Name != cfg.Discriminator {
+					continue
+				}
+
+				if field.Path == cfg.DiscriminatorField {
+					backed = true
+
+					if discriminatorType != nil && !types.AssignableTo(discriminatorType, field.Type) {
+						errs = append(errs, fmt.Errorf("type '%s': field '%s' of subtype '%s' has type %s, which discriminator type '%s' is not assignable to",
+							typeConfig.Type, field.Path, mapping.SubType, types.TypeString(field.Type, types.RelativeTo(pkg)), cfg.DiscriminatorType))
+					}
+
+					continue
+				}
+
+				errs = append(errs, fmt.Errorf("type '%s': field '%s' of subtype '%s' has the JSON name '%s' of the discriminator",
+					typeConfig.Type, field.Path, mapping.SubType, field.Name))
+			}
+
+			if cfg.DiscriminatorField != "" && !backed {
+				errs = append(errs, fmt.Errorf("type '%s': subtype '%s' has no field '%s' with the JSON name '%s'",
+					typeConfig.Type, mapping.SubType, cfg.DiscriminatorField, cfg.Discriminator))
+			}
+		}
+	}
+
+	return warnings, errors.Join(errs...)
+}
+
+// lookupDiscriminatorType returns the Go type of the discriminator, or nil if it is not declared in pkg.
+func lookupDiscriminatorType(cfg *Config, pkg *types.Package) types.Type {
+	switch cfg.DiscriminatorType {
+	case DiscriminatorTypeString:
+		return types.Typ[types.String]
+	case DiscriminatorTypeInt:
+		return types.Typ[types.Int]
+	}
+
+	obj, ok := pkg.Scope().Lookup(cfg.DiscriminatorType).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+
+	return obj.Type()
+}
+
+// checkDiscriminatorConstants reports names of a named discriminator type which are constants of the zero value.
+func checkDiscriminatorConstants(cfg *Config, pkg *types.Package) []error {
+	if cfg.DiscriminatorType == DiscriminatorTypeString || cfg.DiscriminatorType == DiscriminatorTypeInt {
+		return nil
+	}
+
+	var errs []error
+
+	for _, mapping := range cfg.Types {
+		c, ok := pkg.Scope().Lookup(mapping.TypeName).(*types.Const)
+		if !ok || !isZeroConstant(c.Val()) {
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("type '%s': subtype '%s' has name '%s' whose value is zero, read as a missing discriminator",
+			cfg.Type, mapping.SubType, mapping.TypeName))
+	}
+
+	return errs
+}
+
+// isZeroConstant reports whether v is the zero value of its kind.
+func isZeroConstant(v constant.Value) bool {
+	switch v.Kind() {
+	case constant.Int, constant.Float, constant.Complex:
+		return constant.Sign(v) == 0
+	case constant.String:
+		return constant.StringVal(v) == ""
+	case constant.Bool:
+		return !constant.BoolVal(v)
+	default:
+		return false
+	}
+}
+
+// loadPackage parses and type checks the package in dir, leaving out files generated by polygen.
+// Type errors are ignored, so that a package which does not compile yet can still be inspected.
+func loadPackage(dir string) (*types.Package, error) {
+	// The output directory of a new package may not exist yet
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return types.NewPackage(dir, ""), nil
+	}
+
+	buildPkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		var noGo *build.NoGoError
+		if errors.As(err, &noGo) {
+			return types.NewPackage(dir, ""), nil
+		}
+
+		return nil, fmt.Errorf("loading package '%s': %v", dir, err)
+	}
+
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+
+	for _, name := range buildPkg.GoFiles {
+		path := filepath.Join(dir, name)
+
+		generated, err := hasGeneratedHeader(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if generated {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("parsing '%s': %v", path, err)
+		}
+
+		files = append(files, file)
+	}
+
+	conf := types.Config{
+		Importer: fallbackImporter{
+			export: importer.ForCompiler(fset, "gc", nil),
+			source: importer.ForCompiler(fset, "source", nil),
+		},
+		Error: func(error) {},
+	}
+
+	pkg, _ := conf.Check(buildPkg.ImportPath, fset, files, nil)
+
+	return pkg, nil
+}
+
+// fallbackImporter imports packages from export data, which is fast for the standard library,
+// and falls back to type checking the sources of other packages.
+type fallbackImporter struct {
+	export types.Importer
+	source types.Importer
+}
+
+func (i fallbackImporter) Import(path string) (*types.Package, error) {
+	if pkg, err := i.export.Import(path); err == nil {
+		return pkg, nil
+	}
+
+	return i.source.Import(path)
+}
+
+// jsonField is a field of a struct as encoding/json sees it.
+type jsonField struct {
+	// Name is the JSON object key of the field
+	Name string
+	// Path is the Go selector of the field, through embedded structs if promoted
+	Path string
+	// Type is the Go type of the field
+	Type types.Type
+
+	// depth is the number of embedded structs the field is promoted through
+	depth int
+	// tagged reports whether the JSON name comes from a struct tag
+	tagged bool
+}
+
+// jsonFields returns the fields encoding/json marshals for s, following embedded structs without a JSON name.
+// Conflicts between promoted fields are resolved as encoding/json does: the shallowest field of a name wins,
+// preferring a tagged one among fields of the same depth, and the name is dropped if that leaves several.
+func jsonFields(s *types.Struct) []jsonField {
+	candidates := collectJSONFields(s, "", 0, make(map[*types.Struct]bool))
+
+	byName := make(map[string][]jsonField)
+	for _, field := range candidates {
+		byName[field.Name] = append(byName[field.Name], field)
+	}
+
+	var fields []jsonField
+
+	for _, field := range candidates {
+		if dominant, ok := dominantJSONField(byName[field.Name]); ok && dominant.Path == field.Path {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// dominantJSONField returns the field of fields sharing a JSON name that encoding/json marshals, if any.
+func dominantJSONField(fields []jsonField) (jsonField, bool) {
+	depth := fields[0].depth
+	for _, field := range fields[1:] {
+		if field.depth < depth {
+			depth = field.depth
+		}
+	}
+
+	var shallowest, tagged []jsonField
+
+	for _, field := range fields {
+		if field.depth != depth {
+			continue
+		}
+
+		shallowest = append(shallowest, field)
+
+		if field.tagged {
+			tagged = append(tagged, field)
+		}
+	}
+
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	default:
+		return jsonField{}, false
+	}
+}
+
+// collectJSONFields returns every field of s encoding/json considers, including those hidden by others.
+func collectJSONFields(s *types.Struct, prefix string, depth int, visited map[*types.Struct]bool) []jsonField {
+	// Guard against embedding cycles through pointers
+	if visited[s] {
+		return nil
+	}
+
+	visited[s] = true
+	defer delete(visited, s)
+
+	var fields []jsonField
+
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+
+		tag := reflect.StructTag(s.Tag(i)).Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Embedded() && name == "" {
+			typ := field.Type()
+			if ptr, ok := typ.(*types.Pointer); ok {
+				typ = ptr.Elem()
+			}
+
+			if embedded, ok := typ.Underlying().(*types.Struct); ok {
+				fields = append(fields, collectJSONFields(embedded, prefix+field.Name()+".", depth+1, visited)...)
+
+				continue
+			}
+		}
+
+		if !field.Exported() {
+			continue
+		}
+
+		tagged := name != ""
+		if !tagged {
+			name = field.Name()
+		}
+
+		fields = append(fields, jsonField{Name: name, Path: prefix + field.Name(), Type: field.Type(), depth: depth, tagged: tagged})
+	}
+
+	return fields
+}
+
+// jsonFieldAt returns the Go selector of the field at the JSON path through nested structs of s, if there is one.
+func jsonFieldAt(s *types.Struct, path []string) (string, bool) {
+	var selector string
+
+	for i, key := range path {
+		var (
+			field jsonField
+			found bool
+		)
+
+		for _, f := range jsonFields(s) {
+			if f.Name == key {
+				field, found = f, true
+
+				break
+			}
+		}
+
+		if !found {
+			return "", false
+		}
+
+		selector += field.Path
+
+		if i == len(path)-1 {
+			break
+		}
+
+		typ := field.Type
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+
+		nested, ok := typ.Underlying().(*types.Struct)
+		if !ok {
+			return "", false
+		}
+
+		s = nested
+		selector += "."
+	}
+
+	return selector, true
+}
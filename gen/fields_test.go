@@ -0,0 +1,302 @@
+package gen
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckFields(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tempDir, "shape.go"), `package shape
+
+import "time"
+
+type IsShape interface{ isShape() }
+
+type base struct {
+	Kind string `+"`json:\"type\"`"+`
+}
+
+type Circle struct {
+	base
+	Radius float64
+}
+
+type Square struct {
+	Type    string `+"`json:\"type,omitempty\"`"+`
+	Created time.Time
+}
+
+type Triangle struct {
+	Type   string
+	Hidden string `+"`json:\"-\"`"+`
+	Named  base   `+"`json:\"named\"`"+`
+}
+
+type Pentagon struct {
+	base
+	Type string `+"`json:\"type\"`"+`
+}
+
+type left struct {
+	Type string `+"`json:\"type\"`"+`
+}
+
+type right struct {
+	Type string `+"`json:\"type\"`"+`
+}
+
+type Octagon struct {
+	left
+	right
+}
+
+type Meta struct {
+	Kind string `+"`json:\"name\"`"+`
+}
+
+type Hexagon struct {
+	Meta *Meta `+"`json:\"type\"`"+`
+}
+
+func (Circle) isShape()   {}
+func (Square) isShape()   {}
+func (Triangle) isShape() {}
+func (Pentagon) isShape() {}
+func (Octagon) isShape()  {}
+func (Hexagon) isShape()  {}
+`)
+	writeTestFile(t, filepath.Join(tempDir, "shape_polygen.go"), GeneratedHeader+"\npackage shape\n\nthis does not parse\n")
+
+	config := &FileConfig{
+		Dir: tempDir,
+		Types: []FileTypeConfig{
+			{
+				Type:      "Shape",
+				Interface: "IsShape",
+				Package:   "shape",
+				Subtypes: map[string]FileSubtypeConfig{
+					"Circle":   {},
+					"Square":   {},
+					"Triangle": {},
+					"Pentagon": {},
+					"Octagon":  {},
+					"Missing":  {},
+				},
+			},
+			{
+				Type:          "ShapeKinded",
+				Interface:     "IsShape",
+				Package:       "shape",
+				Discriminator: "kind",
+				Subtypes: map[string]FileSubtypeConfig{
+					"Circle": {},
+				},
+			},
+			{
+				Type:               "ShapeFielded",
+				Interface:          "IsShape",
+				Package:            "shape",
+				DiscriminatorField: "base.Kind",
+				Subtypes: map[string]FileSubtypeConfig{
+					"Circle":   {},
+					"Square":   {},
+					"Pentagon": {},
+				},
+			},
+			{
+				Type:          "ShapeNested",
+				Interface:     "IsShape",
+				Package:       "shape",
+				Discriminator: "type.name",
+				Subtypes: map[string]FileSubtypeConfig{
+					"Square":  {},
+					"Hexagon": {},
+				},
+			},
+		},
+	}
+
+	_, err := CheckFields(config)
+	if err == nil {
+		t.Fatal("CheckFields() error = nil, want collisions")
+	}
+
+	want := []string{
+		"type 'Shape': field 'base.Kind' of subtype 'Circle' has the JSON name 'type' of the discriminator",
+		"type 'Shape': field 'Type' of subtype 'Pentagon' has the JSON name 'type' of the discriminator",
+		"type 'Shape': field 'Type' of subtype 'Square' has the JSON name 'type' of the discriminator",
+		"type 'ShapeFielded': field 'Type' of subtype 'Pentagon' has the JSON name 'type' of the discriminator",
+		"type 'ShapeFielded': subtype 'Pentagon' has no field 'base.Kind' with the JSON name 'type'",
+		"type 'ShapeFielded': field 'Type' of subtype 'Square' has the JSON name 'type' of the discriminator",
+		"type 'ShapeFielded': subtype 'Square' has no field 'base.Kind' with the JSON name 'type'",
+		"type 'ShapeNested': field 'Meta.Kind' of subtype 'Hexagon' is at the JSON path 'type.name' of the discriminator",
+	}
+	if got := strings.Split(err.Error(), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckFields() error = %q, want %q", got, want)
+	}
+}
+
+func TestCheckFields_zeroDiscriminatorConstant(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tempDir, "shape.go"), `package shape
+
+type IsShape interface{ isShape() }
+
+type Kind int
+
+const (
+	KindCircle Kind = iota
+	KindSquare
+)
+
+type Circle struct{}
+
+type Square struct{}
+
+func (Circle) isShape() {}
+func (Square) isShape() {}
+`)
+
+	circle, square := "KindCircle", "KindSquare"
+
+	config := &FileConfig{
+		Dir: tempDir,
+		Types: []FileTypeConfig{
+			{
+				Type:              "Shape",
+				Interface:         "IsShape",
+				Package:           "shape",
+				DiscriminatorType: "Kind",
+				Subtypes: map[string]FileSubtypeConfig{
+					"Circle": {Name: &circle},
+					"Square": {Name: &square},
+				},
+			},
+		},
+	}
+
+	_, err := CheckFields(config)
+	if want := "type 'Shape': subtype 'Circle' has name 'KindCircle' whose value is zero, read as a missing discriminator"; err == nil || err.Error() != want {
+		t.Errorf("CheckFields() error = %v, want %q", err, want)
+	}
+}
+
+func TestCheckFields_discriminatorFieldType(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tempDir, "shape.go"), `package shape
+
+type IsShape interface{ isShape() }
+
+type Kind string
+
+const (
+	KindCircle Kind = "circle"
+	KindSquare Kind = "square"
+)
+
+type Circle struct {
+	Kind Kind `+"`json:\"kind\"`"+`
+}
+
+type Square struct {
+	Kind string `+"`json:\"kind\"`"+`
+}
+
+func (Circle) isShape() {}
+func (Square) isShape() {}
+`)
+
+	circle, square := "KindCircle", "KindSquare"
+
+	config := &FileConfig{
+		Dir: tempDir,
+		Types: []FileTypeConfig{
+			{
+				Type:               "Shape",
+				Interface:          "IsShape",
+				Package:            "shape",
+				Discriminator:      "kind",
+				DiscriminatorField: "Kind",
+				Subtypes: map[string]FileSubtypeConfig{
+					"Circle": {},
+					"Square": {},
+				},
+			},
+			{
+				Type:               "ShapeKinded",
+				Interface:          "IsShape",
+				Package:            "shape",
+				Discriminator:      "kind",
+				DiscriminatorType:  "Kind",
+				DiscriminatorField: "Kind",
+				Subtypes: map[string]FileSubtypeConfig{
+					"Circle": {Name: &circle},
+					"Square": {Name: &square},
+				},
+			},
+		},
+	}
+
+	_, err := CheckFields(config)
+	if err == nil {
+		t.Fatal("CheckFields() error = nil, want mismatched field types")
+	}
+
+	want := []string{
+		"type 'Shape': field 'Kind' of subtype 'Circle' has type Kind, which discriminator type 'string' is not assignable to",
+		"type 'ShapeKinded': field 'Kind' of subtype 'Square' has type string, which discriminator type 'Kind' is not assignable to",
+	}
+	if got := strings.Split(err.Error(), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckFields() error = %q, want %q", got, want)
+	}
+}
+
+func TestCheckFields_unparsablePackage(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tempDir, "shape.go"), `package shape
+
+type IsShape interface{ isShape() }
+
+type Circle struct {
+	Type string `+"`json:\"type\"`"+`
+}
+
+func (Circle) isShape() {}
+`)
+
+	// A sibling file in the middle of being edited
+	writeTestFile(t, filepath.Join(tempDir, "square.go"), `package shape
+
+type Square struct {
+`)
+
+	config := &FileConfig{
+		Dir: tempDir,
+		Types: []FileTypeConfig{
+			{
+				Type:      "Shape",
+				Interface: "IsShape",
+				Package:   "shape",
+				Subtypes: map[string]FileSubtypeConfig{
+					"Circle": {},
+				},
+			},
+		},
+	}
+
+	warnings, err := CheckFields(config)
+	if err != nil {
+		t.Fatalf("CheckFields() error = %v, want nil", err)
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "square.go") {
+		t.Errorf("CheckFields() warnings = %q, want one naming square.go", warnings)
+	}
+}
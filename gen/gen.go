@@ -0,0 +1,1008 @@
+// Package gen implements the polygen code generator as a library.
+//
+// A typical use loads and validates a configuration, checks the subtypes, renders the code and writes it:
+//
+//	config, err := gen.LoadConfig(".polygen.json")
+//	if err != nil {
+//		return err
+//	}
+//
+//	if err := gen.Validate(config); err != nil {
+//		return err
+//	}
+//
+//	warnings, err := gen.CheckFields(config)
+//	if err != nil {
+//		return err
+//	}
+//
+//	for _, warning := range warnings {
+//		log.Print(warning)
+//	}
+//
+//	files, err := gen.Render(config)
+//	if err != nil {
+//		return err
+//	}
+//
+//	written, err := gen.Write(files)
+package gen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GeneratedHeader is the first line of every file produced by polygen.
+const GeneratedHeader = "// Code generated by polygen; DO NOT EDIT."
+
+// Status describes what writing a rendered file would do to the file system.
+type Status string
+
+const (
+	StatusCreate    Status = "create"
+	StatusChange    Status = "change"
+	StatusUnchanged Status = "unchanged"
+)
+
+// LoadConfig reads and parses the configuration file at path.
+// The directory of the file becomes the Dir of the returned config.
+func LoadConfig(path string) (*FileConfig, error) {
+	configData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file '%s': %v", path, err)
+	}
+
+	var config FileConfig
+	if err := json.Unmarshal(configData, &config); err != nil {
+		return nil, fmt.Errorf("parsing config file '%s': %v", path, err)
+	}
+
+	config.Dir = filepath.Dir(path)
+
+	return &config, nil
+}
+
+// Validate checks the config for missing required fields, invalid Go identifiers,
+// unknown default subtypes and clashing type names, output paths or gob registrations.
+// All problems found are joined into the returned error.
+func Validate(config *FileConfig) error {
+	var errs []error
+
+	if len(config.Types) == 0 {
+		errs = append(errs, errors.New("no types configured"))
+	}
+
+	outputPaths := make(map[string]string)
+	gobs := &gobRegistry{
+		subtypes: make(map[[2]string]gobRegistration),
+		names:    make(map[[2]string]string),
+	}
+
+	for i := range config.Types {
+		typeConfig := &config.Types[i]
+
+		for _, err := range validateType(config, typeConfig) {
+			errs = append(errs, fmt.Errorf("type '%s': %v", typeConfig.Type, err))
+		}
+
+		for _, outputPath := range OutputPaths(config, typeConfig) {
+			if other, ok := outputPaths[outputPath]; ok {
+				errs = append(errs, fmt.Errorf("type '%s': output path '%s' is already used by type '%s'", typeConfig.Type, outputPath, other))
+
+				continue
+			}
+
+			outputPaths[outputPath] = typeConfig.Type
+		}
+
+		errs = append(errs, validateGob(config, typeConfig, gobs)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateType(config *FileConfig, typeConfig *FileTypeConfig) []error {
+	var errs []error
+
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"type", typeConfig.Type},
+		{"interface", typeConfig.Interface},
+		{"package", typeConfig.Package},
+	} {
+		if field.value == "" {
+			errs = append(errs, fmt.Errorf("missing %s", field.name))
+		} else if !token.IsIdentifier(field.value) {
+			errs = append(errs, fmt.Errorf("%s '%s' is not a valid Go identifier", field.name, field.value))
+		}
+	}
+
+	if len(typeConfig.Subtypes) == 0 {
+		errs = append(errs, errors.New("no subtypes configured"))
+	}
+
+	if typeConfig.DefaultSubtype != "" {
+		if _, ok := typeConfig.Subtypes[typeConfig.DefaultSubtype]; !ok {
+			errs = append(errs, fmt.Errorf("default subtype '%s' is not among the subtypes", typeConfig.DefaultSubtype))
+		}
+	}
+
+	for _, tmplConfig := range typeConfig.Templates {
+		switch {
+		case tmplConfig.Path == "":
+			errs = append(errs, errors.New("template without path"))
+		case tmplConfig.Replace != "" && !isBuiltinTemplate(tmplConfig.Replace):
+			errs = append(errs, fmt.Errorf("template '%s' replaces unknown template '%s'", tmplConfig.Path, tmplConfig.Replace))
+		case tmplConfig.Replace != "" && tmplConfig.Filename != "":
+			errs = append(errs, fmt.Errorf("template '%s' cannot both replace a template and have a filename", tmplConfig.Path))
+		case tmplConfig.Replace == "" && tmplConfig.Filename == "":
+			errs = append(errs, fmt.Errorf("template '%s' needs either replace or filename", tmplConfig.Path))
+		}
+	}
+
+	cfg := convertFileConfigToConfig(typeConfig, config)
+
+	for _, key := range cfg.DiscriminatorPath() {
+		if key == "" {
+			errs = append(errs, fmt.Errorf("discriminator '%s' has an empty key", cfg.Discriminator))
+
+			break
+		}
+	}
+
+	errs = append(errs, validateDiscriminatorType(cfg, typeConfig)...)
+	errs = append(errs, validateDiscriminatorMatch(cfg)...)
+
+	if cfg.DiscriminatorField != "" {
+		for _, name := range strings.Split(cfg.DiscriminatorField, ".") {
+			if !token.IsIdentifier(name) {
+				errs = append(errs, fmt.Errorf("discriminator field '%s' is not a Go field selector", cfg.DiscriminatorField))
+
+				break
+			}
+		}
+
+		if cfg.IsNestedDiscriminator() {
+			errs = append(errs, fmt.Errorf("discriminator field '%s' cannot back the nested discriminator '%s'", cfg.DiscriminatorField, cfg.Discriminator))
+		}
+	}
+
+	errs = append(errs, validateVersions(cfg)...)
+
+	if cfg.MarshalNil != MarshalNilNull && cfg.MarshalNil != MarshalNilError {
+		errs = append(errs, fmt.Errorf("unknown marshalNil policy '%s'", cfg.MarshalNil))
+	}
+
+	errs = append(errs, validateXML(cfg)...)
+	errs = append(errs, validateProto(cfg, typeConfig.Proto)...)
+
+	switch cfg.UnmarshalNull {
+	case UnmarshalNullZero, UnmarshalNullError:
+	case UnmarshalNullDefault:
+		if typeConfig.DefaultSubtype == "" {
+			errs = append(errs, errors.New("unmarshalNull policy 'default' needs a default subtype"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown unmarshalNull policy '%s'", cfg.UnmarshalNull))
+	}
+
+	typeNames := make(map[string]string)
+
+	for _, mapping := range cfg.Types {
+		if !token.IsIdentifier(mapping.SubType) {
+			errs = append(errs, fmt.Errorf("subtype '%s' is not a valid Go identifier", mapping.SubType))
+		}
+
+		if other, ok := typeNames[mapping.TypeName]; ok {
+			errs = append(errs, fmt.Errorf("subtypes '%s' and '%s' share the name '%s'", other, mapping.SubType, mapping.TypeName))
+
+			continue
+		}
+
+		typeNames[mapping.TypeName] = mapping.SubType
+	}
+
+	return errs
+}
+
+// gobRegistration is a subtype registered with encoding/gob by the generated code of a type.
+type gobRegistration struct {
+	// Type is the name of the type whose generated code registers the subtype
+	Type string
+	// Name is the name the subtype is registered under
+	Name string
+	// IsPointer indicates if the subtype is registered as a pointer
+	IsPointer bool
+}
+
+// gobRegistry holds the gob registrations of the validated types, keyed by output directory and subtype or name.
+type gobRegistry struct {
+	subtypes map[[2]string]gobRegistration
+	names    map[[2]string]string
+}
+
+// validateGob checks that the subtypes typeConfig registers with encoding/gob do not clash with those
+// registered by other types of the same package, which would make the registration panic.
+func validateGob(config *FileConfig, typeConfig *FileTypeConfig, registry *gobRegistry) []error {
+	cfg := convertFileConfigToConfig(typeConfig, config)
+	if !cfg.Gob {
+		return nil
+	}
+
+	var errs []error
+
+	dir := filepath.Dir(filepath.Clean(getOutputPath(typeConfig, config.Dir)))
+
+	for _, mapping := range cfg.Types {
+		reg := gobRegistration{Type: cfg.Type, Name: cfg.GobName(mapping.TypeName), IsPointer: mapping.IsPointer}
+
+		if other, ok := registry.subtypes[[2]string{dir, mapping.SubType}]; ok && (other.Name != reg.Name || other.IsPointer != reg.IsPointer) {
+			errs = append(errs, fmt.Errorf("type '%s': subtype '%s' is already registered with gob as '%s' by type '%s'", cfg.Type, mapping.SubType, other.Name, other.Type))
+
+			continue
+		}
+
+		if other, ok := registry.names[[2]string{dir, reg.Name}]; ok && other != mapping.SubType {
+			errs = append(errs, fmt.Errorf("type '%s': gob name '%s' is already registered for subtype '%s'", cfg.Type, reg.Name, other))
+
+			continue
+		}
+
+		registry.subtypes[[2]string{dir, mapping.SubType}] = reg
+		registry.names[[2]string{dir, reg.Name}] = mapping.SubType
+	}
+
+	return errs
+}
+
+// isBuiltinTemplate reports whether name is a built-in template that user-supplied templates can replace.
+func isBuiltinTemplate(name string) bool {
+	switch name {
+	case JSONVersionV1, JSONVersionV2, TemplateXML, TemplateYAML, TemplateGob, TemplateSQL, TemplateCBOR,
+		TemplateSlog, TemplateEqual, TemplateClone, TemplateContainers, TemplateDecoder, TemplateDecoderIter:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateDiscriminatorType checks that the subtype names are valid values of the discriminator type.
+func validateDiscriminatorType(cfg *Config, typeConfig *FileTypeConfig) []error {
+	if cfg.DiscriminatorType == DiscriminatorTypeString {
+		return nil
+	}
+
+	var errs []error
+
+	if cfg.DiscriminatorType != DiscriminatorTypeInt && !token.IsIdentifier(cfg.DiscriminatorType) {
+		errs = append(errs, fmt.Errorf("discriminator type '%s' is neither string, int nor a Go identifier", cfg.DiscriminatorType))
+	}
+
+	values := make(map[int64]string)
+
+	for _, mapping := range cfg.Types {
+		if typeConfig.Subtypes[mapping.SubType].Name == nil {
+			errs = append(errs, fmt.Errorf("subtype '%s' needs an explicit name for discriminator type '%s'", mapping.SubType, cfg.DiscriminatorType))
+
+			continue
+		}
+
+		if cfg.DiscriminatorType != DiscriminatorTypeInt {
+			// Constants of the named type are checked against the package by CheckFields
+			if value, err := strconv.ParseInt(mapping.TypeName, 0, 64); err == nil && value == 0 {
+				errs = append(errs, fmt.Errorf("subtype '%s' has name '%s' which is the zero value, read as a missing discriminator", mapping.SubType, mapping.TypeName))
+			}
+
+			continue
+		}
+
+		value, err := strconv.ParseInt(mapping.TypeName, 0, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("subtype '%s' has name '%s' which is not an integer", mapping.SubType, mapping.TypeName))
+
+			continue
+		}
+
+		if value == 0 {
+			errs = append(errs, fmt.Errorf("subtype '%s' has name '%s' which is the zero value, read as a missing discriminator", mapping.SubType, mapping.TypeName))
+
+			continue
+		}
+
+		if other, ok := values[value]; ok {
+			errs = append(errs, fmt.Errorf("subtypes '%s' and '%s' share the value %d", other, mapping.SubType, value))
+
+			continue
+		}
+
+		values[value] = mapping.SubType
+	}
+
+	return errs
+}
+
+func validateDiscriminatorMatch(cfg *Config) []error {
+	switch cfg.DiscriminatorMatch {
+	case DiscriminatorMatchExact:
+		return nil
+	case DiscriminatorMatchCaseInsensitive, DiscriminatorMatchNormalized:
+	default:
+		return []error{fmt.Errorf("unknown discriminator match '%s'", cfg.DiscriminatorMatch)}
+	}
+
+	if cfg.DiscriminatorType != DiscriminatorTypeString {
+		return []error{fmt.Errorf("discriminator match '%s' needs discriminator type 'string'", cfg.DiscriminatorMatch)}
+	}
+
+	var errs []error
+
+	keys := make(map[string]string)
+
+	for _, mapping := range cfg.Types {
+		key := cfg.DiscriminatorMatchKey(mapping.TypeName)
+
+		if other, ok := keys[key]; ok {
+			errs = append(errs, fmt.Errorf("subtypes '%s' and '%s' both match '%s'", other, mapping.SubType, key))
+
+			continue
+		}
+
+		keys[key] = mapping.SubType
+	}
+
+	return errs
+}
+
+func validateVersions(cfg *Config) []error {
+	var errs []error
+
+	if cfg.VersionField != "" {
+		if cfg.VersionField == cfg.Discriminator {
+			errs = append(errs, fmt.Errorf("version field '%s' is the discriminator", cfg.VersionField))
+		}
+
+		if cfg.IsNestedDiscriminator() || cfg.DiscriminatorField != "" {
+			errs = append(errs, errors.New("version field cannot be used with a nested discriminator or a discriminator field"))
+		}
+	}
+
+	for _, mapping := range cfg.Types {
+		switch {
+		case mapping.Version < 0:
+			errs = append(errs, fmt.Errorf("subtype '%s' has negative version %d", mapping.SubType, mapping.Version))
+		case mapping.Version > 0 && cfg.VersionField == "":
+			errs = append(errs, fmt.Errorf("subtype '%s' has a version but no version field is configured", mapping.SubType))
+		case mapping.Upgrade != "" && mapping.Version == 0:
+			errs = append(errs, fmt.Errorf("subtype '%s' has an upgrade function but no version", mapping.SubType))
+		}
+
+		if mapping.Upgrade != "" && !token.IsIdentifier(mapping.Upgrade) {
+			errs = append(errs, fmt.Errorf("subtype '%s' has upgrade function '%s' which is not a valid Go identifier", mapping.SubType, mapping.Upgrade))
+		}
+	}
+
+	return errs
+}
+
+func validateXML(cfg *Config) []error {
+	switch cfg.XML {
+	case "":
+		return nil
+	case XMLAttribute, XMLElement:
+	default:
+		return []error{fmt.Errorf("unknown xml mode '%s'", cfg.XML)}
+	}
+
+	var errs []error
+
+	if cfg.DiscriminatorType != DiscriminatorTypeString {
+		errs = append(errs, fmt.Errorf("xml mode '%s' needs discriminator type 'string'", cfg.XML))
+	}
+
+	if cfg.XML == XMLAttribute {
+		if cfg.IsNestedDiscriminator() || !isXMLName(cfg.Discriminator) {
+			errs = append(errs, fmt.Errorf("discriminator '%s' is not a valid XML attribute name", cfg.Discriminator))
+		}
+
+		return errs
+	}
+
+	for _, mapping := range cfg.Types {
+		if !isXMLName(mapping.TypeName) {
+			errs = append(errs, fmt.Errorf("subtype '%s' has name '%s' which is not a valid XML element name", mapping.SubType, mapping.TypeName))
+		}
+	}
+
+	return errs
+}
+
+// validateProto checks the proto config of a type and the field numbers pinned for its subtypes.
+func validateProto(cfg *Config, proto *FileProtoConfig) []error {
+	var errs []error
+
+	if proto == nil {
+		for _, mapping := range cfg.Types {
+			if mapping.ProtoNumber != 0 {
+				errs = append(errs, fmt.Errorf("subtype '%s' has a protoNumber but the type has no proto config", mapping.SubType))
+			}
+		}
+
+		return errs
+	}
+
+	if proto.Package == "" {
+		errs = append(errs, errors.New("missing proto package"))
+	} else if !isProtoFullName(proto.Package) {
+		errs = append(errs, fmt.Errorf("proto package '%s' is not a valid protobuf package name", proto.Package))
+	}
+
+	if proto.GoPackage == "" {
+		errs = append(errs, errors.New("missing proto goPackage"))
+	}
+
+	if proto.Filename != "" && (filepath.Base(proto.Filename) != proto.Filename || filepath.Ext(proto.Filename) != ".proto") {
+		errs = append(errs, fmt.Errorf("proto filename '%s' is not a .proto file name", proto.Filename))
+	}
+
+	numbers := make(map[int]string)
+
+	for _, mapping := range cfg.Types {
+		number := mapping.ProtoNumber
+		if number == 0 {
+			continue
+		}
+
+		// Numbers 19000 to 19999 are reserved for the protobuf implementation
+		if number < 0 || number > 536870911 || number >= 19000 && number <= 19999 {
+			errs = append(errs, fmt.Errorf("subtype '%s' has protoNumber %d which is not a valid protobuf field number", mapping.SubType, number))
+
+			continue
+		}
+
+		if other, ok := numbers[number]; ok {
+			errs = append(errs, fmt.Errorf("subtype '%s' has protoNumber %d which is already used by subtype '%s'", mapping.SubType, number, other))
+
+			continue
+		}
+
+		numbers[number] = mapping.SubType
+	}
+
+	return errs
+}
+
+// isProtoFullName reports whether name is a dot-separated sequence of protobuf identifiers.
+func isProtoFullName(name string) bool {
+	for _, ident := range strings.Split(name, ".") {
+		if ident == "" || isASCIIDigit(ident[0]) {
+			return false
+		}
+
+		for i := 0; i < len(ident); i++ {
+			if c := ident[i]; c != '_' && !isASCIILower(c) && !isASCIIDigit(c) && (c < 'A' || c > 'Z') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isXMLName reports whether name is a valid unqualified XML name, limited to letters, digits, '_', '-' and '.'.
+func isXMLName(name string) bool {
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+
+	return name != ""
+}
+
+// outputTemplate is a template together with the path of the file it renders.
+type outputTemplate struct {
+	// Path is the output file path
+	Path string
+	// Builtin is the built-in template text, used unless File is set
+	Builtin string
+	// File is the path of a user-supplied template
+	File string
+}
+
+// outputTemplates returns the templates rendered for typeConfig, built-in ones first.
+func outputTemplates(config *FileConfig, typeConfig *FileTypeConfig) []outputTemplate {
+	cfg := convertFileConfigToConfig(typeConfig, config)
+
+	outputPath := filepath.Clean(getOutputPath(typeConfig, config.Dir))
+
+	v1 := outputTemplate{Path: outputPath, Builtin: codeTemplate}
+	v2 := outputTemplate{Path: outputPath, Builtin: codeTemplateJSONV2}
+	xml := outputTemplate{Path: getOutputPathXML(outputPath), Builtin: codeTemplateXML}
+	yaml := outputTemplate{Path: getOutputPathYAML(outputPath), Builtin: codeTemplateYAML}
+	gob := outputTemplate{Path: getOutputPathGob(outputPath), Builtin: codeTemplateGob}
+	sql := outputTemplate{Path: getOutputPathSQL(outputPath), Builtin: codeTemplateSQL}
+	cbor := outputTemplate{Path: getOutputPathCBOR(outputPath), Builtin: codeTemplateCBOR}
+	slog := outputTemplate{Path: getOutputPathSlog(outputPath), Builtin: codeTemplateSlog}
+	equal := outputTemplate{Path: getOutputPathEqual(outputPath), Builtin: codeTemplateEqual}
+	clone := outputTemplate{Path: getOutputPathClone(outputPath), Builtin: codeTemplateClone}
+	containers := outputTemplate{Path: getOutputPathContainers(outputPath), Builtin: codeTemplateContainers}
+	decoder := outputTemplate{Path: getOutputPathDecoder(outputPath), Builtin: codeTemplateDecoder}
+	decoderIter := outputTemplate{Path: getOutputPathDecoderIter(outputPath), Builtin: codeTemplateDecoderIter}
+
+	var extra []outputTemplate
+
+	for _, tmplConfig := range typeConfig.Templates {
+		file := filepath.Join(config.Dir, tmplConfig.Path)
+
+		switch tmplConfig.Replace {
+		case JSONVersionV1:
+			v1.File = file
+		case JSONVersionV2:
+			v2.File = file
+		case TemplateXML:
+			xml.File = file
+		case TemplateYAML:
+			yaml.File = file
+		case TemplateGob:
+			gob.File = file
+		case TemplateSQL:
+			sql.File = file
+		case TemplateCBOR:
+			cbor.File = file
+		case TemplateSlog:
+			slog.File = file
+		case TemplateEqual:
+			equal.File = file
+		case TemplateClone:
+			clone.File = file
+		case TemplateContainers:
+			containers.File = file
+		case TemplateDecoder:
+			decoder.File = file
+		case TemplateDecoderIter:
+			decoderIter.File = file
+		case "":
+			if tmplConfig.Filename == "" {
+				continue // Reported by Validate
+			}
+
+			extra = append(extra, outputTemplate{
+				Path: filepath.Join(filepath.Dir(outputPath), tmplConfig.Filename),
+				File: file,
+			})
+		}
+	}
+
+	var templates []outputTemplate
+
+	switch cfg.JSONVersion {
+	case JSONVersionBoth:
+		v2.Path = getOutputPathJSONV2(outputPath)
+		templates = append(templates, v1, v2)
+	case JSONVersionV2:
+		templates = append(templates, v2)
+	default: // JSONVersionV1 or fallback
+		templates = append(templates, v1)
+	}
+
+	if cfg.XML != "" {
+		templates = append(templates, xml)
+	}
+
+	if cfg.YAML {
+		templates = append(templates, yaml)
+	}
+
+	if cfg.Gob {
+		templates = append(templates, gob)
+	}
+
+	if cfg.SQL {
+		templates = append(templates, sql)
+	}
+
+	if cfg.CBOR {
+		templates = append(templates, cbor)
+	}
+
+	if cfg.Slog {
+		templates = append(templates, slog)
+	}
+
+	if cfg.Equal {
+		templates = append(templates, equal)
+	}
+
+	if cfg.Clone {
+		templates = append(templates, clone)
+	}
+
+	if cfg.Containers {
+		templates = append(templates, containers)
+	}
+
+	if cfg.ArrayDecoder {
+		templates = append(templates, decoder, decoderIter)
+	}
+
+	return append(templates, extra...)
+}
+
+// OutputPaths returns the paths of the files generated for typeConfig, relative to the working directory.
+func OutputPaths(config *FileConfig, typeConfig *FileTypeConfig) []string {
+	templates := outputTemplates(config, typeConfig)
+
+	paths := make([]string, len(templates))
+	for i, tmpl := range templates {
+		paths[i] = tmpl.Path
+	}
+
+	if typeConfig.Proto != nil {
+		protoPath, goPath, lockPath := getOutputPathProto(filepath.Clean(getOutputPath(typeConfig, config.Dir)), typeConfig.Proto)
+		paths = append(paths, protoPath, goPath, lockPath)
+	}
+
+	return paths
+}
+
+// Render generates the code of every configured type without writing it.
+// The result maps output paths to the generated code. For types with a proto config,
+// the package of the type is type checked to map the fields of the subtypes to protobuf messages.
+func Render(config *FileConfig) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	packages := make(map[string]*types.Package)
+
+	for i := range config.Types {
+		typeConfig := &config.Types[i]
+
+		cfg := convertFileConfigToConfig(typeConfig, config)
+
+		for _, tmpl := range outputTemplates(config, typeConfig) {
+			name, text := "code", tmpl.Builtin
+
+			if tmpl.File != "" {
+				data, err := os.ReadFile(tmpl.File)
+				if err != nil {
+					return nil, fmt.Errorf("reading template for type '%s': %v", cfg.Type, err)
+				}
+
+				name, text = filepath.Base(tmpl.File), string(data)
+			}
+
+			code, err := executeTemplate(name, text, cfg, strings.HasSuffix(tmpl.Path, ".go"))
+			if err != nil {
+				return nil, fmt.Errorf("generating code for type '%s' from template '%s': %v", cfg.Type, name, err)
+			}
+
+			if _, ok := files[tmpl.Path]; ok {
+				return nil, fmt.Errorf("generating code for type '%s': output path '%s' is used twice", cfg.Type, tmpl.Path)
+			}
+
+			files[tmpl.Path] = code
+		}
+
+		if typeConfig.Proto != nil {
+			if err := renderProto(config, typeConfig, cfg, packages, files); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// RenderProto generates just the protobuf files of the configured types with a proto config: the .proto file,
+// the Go conversion functions and the lock file with the field numbers. Types without a proto config are left out.
+func RenderProto(config *FileConfig) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	packages := make(map[string]*types.Package)
+
+	for i := range config.Types {
+		typeConfig := &config.Types[i]
+
+		if typeConfig.Proto == nil {
+			continue
+		}
+
+		if err := renderProto(config, typeConfig, convertFileConfigToConfig(typeConfig, config), packages, files); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// renderProto adds the .proto file, the Go conversion functions and the lock file of typeConfig to files,
+// loading the package of the type into packages unless it is there already. The field numbers are taken
+// from the lock file written by earlier runs, so that they stay the same as the Go types change.
+func renderProto(config *FileConfig, typeConfig *FileTypeConfig, cfg *Config, packages map[string]*types.Package, files map[string][]byte) error {
+	outputPath := filepath.Clean(getOutputPath(typeConfig, config.Dir))
+	dir := filepath.Dir(outputPath)
+
+	pkg, ok := packages[dir]
+	if !ok {
+		var err error
+
+		pkg, err = loadPackage(dir)
+		if err != nil {
+			return fmt.Errorf("type '%s': %v", cfg.Type, err)
+		}
+
+		packages[dir] = pkg
+	}
+
+	protoPath, goPath, lockPath := getOutputPathProto(outputPath, typeConfig.Proto)
+
+	lock, err := readProtoLock(lockPath)
+	if err != nil {
+		return fmt.Errorf("type '%s': %v", cfg.Type, err)
+	}
+
+	file, err := newProtoFile(cfg, typeConfig.Proto, pkg, lock)
+	if err != nil {
+		return fmt.Errorf("mapping type '%s' to protobuf: %v", cfg.Type, err)
+	}
+
+	proto, code, err := generateProto(file)
+	if err != nil {
+		return fmt.Errorf("generating protobuf code for type '%s': %v", cfg.Type, err)
+	}
+
+	lockData, err := json.MarshalIndent(lock, "", "    ")
+	if err != nil {
+		return fmt.Errorf("generating protobuf lock file for type '%s': %v", cfg.Type, err)
+	}
+
+	for path, data := range map[string][]byte{protoPath: proto, goPath: code, lockPath: append(lockData, '\n')} {
+		if _, ok := files[path]; ok {
+			return fmt.Errorf("generating protobuf code for type '%s': output path '%s' is used twice", cfg.Type, path)
+		}
+
+		files[path] = data
+	}
+
+	return nil
+}
+
+// Write writes the rendered files, skipping those whose content is already up to date,
+// and returns the number of files actually written.
+// Each file is written to a temporary file first which is then renamed, so a crash never leaves a truncated file.
+func Write(files map[string][]byte) (int, error) {
+	var written int
+
+	for path, code := range files {
+		isWritten, err := writeFile(path, code)
+		if err != nil {
+			return written, err
+		}
+
+		if isWritten {
+			written++
+		}
+	}
+
+	return written, nil
+}
+
+func writeFile(path string, code []byte) (bool, error) {
+	status, err := FileStatus(path, code)
+	if err != nil {
+		return false, err
+	}
+
+	if status == StatusUnchanged {
+		return false, nil
+	}
+
+	dir := filepath.Dir(path)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, fmt.Errorf("creating output directory '%s': %v", dir, err)
+	}
+
+	// An existing file keeps its mode, a new one gets 0644 restricted by the umask as with os.WriteFile
+	perm, keepMode := fs.FileMode(0o644), false
+	if info, err := os.Stat(path); err == nil {
+		perm, keepMode = info.Mode().Perm(), true
+	}
+
+	tmp, err := createTempFile(dir, filepath.Base(path), perm)
+	if err != nil {
+		return false, fmt.Errorf("creating temporary file for '%s': %v", path, err)
+	}
+
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op after a successful rename
+
+	if _, err := tmp.Write(code); err != nil {
+		tmp.Close()
+
+		return false, fmt.Errorf("writing generated code to '%s': %v", tmpPath, err)
+	}
+
+	if keepMode {
+		if err := tmp.Chmod(perm); err != nil {
+			tmp.Close()
+
+			return false, fmt.Errorf("setting permissions of '%s': %v", tmpPath, err)
+		}
+	}
+
+	if err := tmp.Close(); err != nil {
+		return false, fmt.Errorf("closing '%s': %v", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return false, fmt.Errorf("writing generated code to '%s': %v", path, err)
+	}
+
+	return true, nil
+}
+
+// createTempFile creates a new hidden file next to the file named base in dir with the permissions perm,
+// which unlike os.CreateTemp are subject to the umask.
+func createTempFile(dir, base string, perm fs.FileMode) (*os.File, error) {
+	for i := 0; i < 100; i++ {
+		path := filepath.Join(dir, "."+base+"."+strconv.FormatUint(uint64(rand.Uint32()), 10)+".tmp")
+
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+
+		return f, err
+	}
+
+	return nil, fmt.Errorf("no unused temporary file name in '%s'", dir)
+}
+
+// FileStatus reports whether writing code to path would create, change or leave the file unchanged.
+func FileStatus(path string, code []byte) (Status, error) {
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return StatusCreate, nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("reading existing file '%s': %v", path, err)
+	}
+
+	if bytes.Equal(existing, code) {
+		return StatusUnchanged, nil
+	}
+
+	return StatusChange, nil
+}
+
+// Orphans returns the Go and protobuf files carrying GeneratedHeader that are not among files. It walks dir, skipping
+// hidden, vendor and testdata directories as well as subdirectories with a config file named configName
+// of their own, whose generated files belong to that config. Directories outside dir that files are
+// written to are scanned too, without descending into their subdirectories.
+func Orphans(dir, configName string, files map[string][]byte) ([]string, error) {
+	var orphans []string
+
+	check := func(path string) error {
+		if ext := filepath.Ext(path); ext != ".go" && ext != ".proto" {
+			return nil
+		}
+
+		if _, ok := files[filepath.Clean(path)]; ok {
+			return nil
+		}
+
+		isGenerated, err := hasGeneratedHeader(path)
+		if err != nil {
+			return err
+		}
+
+		if isGenerated {
+			orphans = append(orphans, path)
+		}
+
+		return nil
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return check(path)
+		}
+
+		if path == dir {
+			return nil
+		}
+
+		if name := d.Name(); strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" {
+			return filepath.SkipDir
+		}
+
+		if _, err := os.Stat(filepath.Join(path, configName)); err == nil {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning '%s': %v", dir, err)
+	}
+
+	for _, outDir := range outsideDirs(dir, files) {
+		entries, err := os.ReadDir(outDir)
+		if err != nil {
+			return nil, fmt.Errorf("scanning '%s': %v", outDir, err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			if err := check(filepath.Join(outDir, entry.Name())); err != nil {
+				return nil, fmt.Errorf("scanning '%s': %v", outDir, err)
+			}
+		}
+	}
+
+	return orphans, nil
+}
+
+// outsideDirs returns the sorted directories files are written to that are not under dir.
+func outsideDirs(dir string, files map[string][]byte) []string {
+	seen := make(map[string]bool)
+
+	var dirs []string
+
+	for path := range files {
+		outDir := filepath.Dir(filepath.Clean(path))
+		if rel, err := filepath.Rel(dir, outDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		if !seen[outDir] {
+			seen[outDir] = true
+			dirs = append(dirs, outDir)
+		}
+	}
+
+	sort.Strings(dirs)
+
+	return dirs
+}
+
+func hasGeneratedHeader(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("opening '%s': %v", path, err)
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("reading '%s': %v", path, err)
+	}
+
+	return strings.TrimRight(line, "\r\n") == GeneratedHeader, nil
+}
@@ -0,0 +1,812 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, ".polygen.json")
+	writeTestFile(t, configFile, `{"defaultDiscriminator": "kind", "types": [{"type": "Item"}]}`)
+
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	want := &FileConfig{
+		Dir:                  tempDir,
+		DefaultDiscriminator: "kind",
+		Types:                []FileTypeConfig{{Type: "Item"}},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("LoadConfig() = %+v, want %+v", config, want)
+	}
+
+	if _, err := LoadConfig(filepath.Join(tempDir, "missing.json")); err == nil {
+		t.Error("LoadConfig() expected error for a missing file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	name, one, oneHex, circle := "same", "1", "0x1", "circle"
+	zero, zeroHex, kindSquare := "0", "0x0", "ShapeKindSquare"
+	pointer := true
+
+	tests := []struct {
+		name    string
+		config  *FileConfig
+		wantErr []string
+	}{
+		{
+			name: "valid",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:           "Shape",
+						Interface:      "IsShape",
+						Package:        "main",
+						DefaultSubtype: "Circle",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle":    {},
+							"Rectangle": {},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "no types",
+			config:  &FileConfig{},
+			wantErr: []string{"no types configured"},
+		},
+		{
+			name: "missing fields",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{Type: "Shape"},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': missing interface",
+				"type 'Shape': missing package",
+				"type 'Shape': no subtypes configured",
+			},
+		},
+		{
+			name: "invalid identifiers and default subtype",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:           "Shape",
+						Interface:      "Is-Shape",
+						Package:        "main",
+						DefaultSubtype: "Square",
+						Subtypes: map[string]FileSubtypeConfig{
+							"*Circle": {},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': interface 'Is-Shape' is not a valid Go identifier",
+				"type 'Shape': default subtype 'Square' is not among the subtypes",
+				"type 'Shape': subtype '*Circle' is not a valid Go identifier",
+			},
+		},
+		{
+			name: "duplicate names",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:      "Shape",
+						Interface: "IsShape",
+						Package:   "main",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle":    {Name: &name},
+							"Rectangle": {Name: &name},
+						},
+					},
+					{
+						Type:      "Other",
+						Interface: "IsShape",
+						Package:   "main",
+						Filename:  "shape_polygen.go",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': subtypes 'Circle' and 'Rectangle' share the name 'same'",
+				"type 'Other': output path 'shape_polygen.go' is already used by type 'Shape'",
+			},
+		},
+		{
+			name: "int discriminator",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:              "Shape",
+						Interface:         "IsShape",
+						Package:           "main",
+						DiscriminatorType: DiscriminatorTypeInt,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle":   {Name: &one},
+							"Polygon":  {},
+							"Square":   {Name: &oneHex},
+							"Triangle": {Name: &name},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': subtype 'Polygon' needs an explicit name for discriminator type 'int'",
+				"type 'Shape': subtypes 'Circle' and 'Square' share the value 1",
+				"type 'Shape': subtype 'Triangle' has name 'same' which is not an integer",
+			},
+		},
+		{
+			name: "zero discriminator",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:              "Shape",
+						Interface:         "IsShape",
+						Package:           "main",
+						DiscriminatorType: DiscriminatorTypeInt,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {Name: &zero},
+							"Square": {Name: &one},
+						},
+					},
+					{
+						Type:              "Shape2",
+						Interface:         "IsShape",
+						Package:           "main",
+						DiscriminatorType: "ShapeKind",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {Name: &zeroHex},
+							"Square": {Name: &kindSquare},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': subtype 'Circle' has name '0' which is the zero value, read as a missing discriminator",
+				"type 'Shape2': subtype 'Circle' has name '0x0' which is the zero value, read as a missing discriminator",
+			},
+		},
+		{
+			name: "empty discriminator key",
+			config: &FileConfig{
+				DefaultDiscriminator: "meta..type",
+				Types: []FileTypeConfig{
+					{
+						Type:      "Shape",
+						Interface: "IsShape",
+						Package:   "main",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+					{
+						Type:          "Shape2",
+						Interface:     "IsShape",
+						Package:       "main",
+						Discriminator: "/meta/type",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': discriminator 'meta..type' has an empty key",
+			},
+		},
+		{
+			name: "discriminator match",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:               "Shape",
+						Interface:          "IsShape",
+						Package:            "main",
+						DiscriminatorMatch: DiscriminatorMatchNormalized,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle":     {},
+							"Subcircle":  {},
+							"SubCircle":  {},
+							"Sub_Circle": {},
+						},
+					},
+					{
+						Type:               "Shape2",
+						Interface:          "IsShape",
+						Package:            "main",
+						DiscriminatorMatch: "fuzzy",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+					{
+						Type:               "Shape3",
+						Interface:          "IsShape",
+						Package:            "main",
+						DiscriminatorType:  DiscriminatorTypeInt,
+						DiscriminatorMatch: DiscriminatorMatchCaseInsensitive,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {Name: &one},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': subtypes 'SubCircle' and 'Sub_Circle' both match 'sub_circle'",
+				"type 'Shape2': unknown discriminator match 'fuzzy'",
+				"type 'Shape3': discriminator match 'case-insensitive' needs discriminator type 'string'",
+			},
+		},
+		{
+			name: "null policies",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:          "Shape",
+						Interface:     "IsShape",
+						Package:       "main",
+						MarshalNil:    "omit",
+						UnmarshalNull: UnmarshalNullDefault,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+					{
+						Type:          "Shape2",
+						Interface:     "IsShape",
+						Package:       "main",
+						UnmarshalNull: "skip",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': unknown marshalNil policy 'omit'",
+				"type 'Shape': unmarshalNull policy 'default' needs a default subtype",
+				"type 'Shape2': unknown unmarshalNull policy 'skip'",
+			},
+		},
+		{
+			name: "discriminator field",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:               "Shape",
+						Interface:          "IsShape",
+						Package:            "main",
+						DiscriminatorField: "Meta.",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+					{
+						Type:               "Shape2",
+						Interface:          "IsShape",
+						Package:            "main",
+						Discriminator:      "meta.kind",
+						DiscriminatorField: "Meta.Kind",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': discriminator field 'Meta.' is not a Go field selector",
+				"type 'Shape2': discriminator field 'Meta.Kind' cannot back the nested discriminator 'meta.kind'",
+			},
+		},
+		{
+			name: "versions",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:      "Shape",
+						Interface: "IsShape",
+						Package:   "main",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {Version: 2},
+						},
+					},
+					{
+						Type:         "Shape2",
+						Interface:    "IsShape",
+						Package:      "main",
+						VersionField: "type",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle":  {Version: -1},
+							"Polygon": {Upgrade: "upgradePolygon"},
+							"Square":  {Version: 2, Upgrade: "upgrade-square"},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': subtype 'Circle' has a version but no version field is configured",
+				"type 'Shape2': version field 'type' is the discriminator",
+				"type 'Shape2': subtype 'Circle' has negative version -1",
+				"type 'Shape2': subtype 'Polygon' has an upgrade function but no version",
+				"type 'Shape2': subtype 'Square' has upgrade function 'upgrade-square' which is not a valid Go identifier",
+			},
+		},
+		{
+			name: "xml",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:      "Shape",
+						Interface: "IsShape",
+						Package:   "main",
+						XML:       "json",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+					{
+						Type:          "Shape2",
+						Interface:     "IsShape",
+						Package:       "main",
+						Discriminator: "meta.type",
+						XML:           XMLAttribute,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {},
+						},
+					},
+					{
+						Type:              "Shape3",
+						Interface:         "IsShape",
+						Package:           "main",
+						DiscriminatorType: DiscriminatorTypeInt,
+						XML:               XMLElement,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {Name: &one},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': unknown xml mode 'json'",
+				"type 'Shape2': discriminator 'meta.type' is not a valid XML attribute name",
+				"type 'Shape3': xml mode 'element' needs discriminator type 'string'",
+				"type 'Shape3': subtype 'Circle' has name '1' which is not a valid XML element name",
+			},
+		},
+		{
+			name: "gob",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:      "Shape",
+						Interface: "IsShape",
+						Package:   "main",
+						Gob:       true,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle":  {},
+							"Polygon": {Pointer: &pointer},
+						},
+					},
+					{
+						Type:      "Shape2",
+						Interface: "IsShape",
+						Package:   "main",
+						Gob:       true,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Polygon": {},
+							"Square":  {Name: &circle},
+						},
+					},
+					{
+						Type:      "Shape3",
+						Interface: "IsShape",
+						Package:   "main",
+						Directory: "other",
+						Gob:       true,
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {Name: &one},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape2': subtype 'Polygon' is already registered with gob as 'main.polygon' by type 'Shape'",
+				"type 'Shape2': gob name 'main.circle' is already registered for subtype 'Circle'",
+			},
+		},
+		{
+			name: "invalid proto",
+			config: &FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:      "Shape",
+						Interface: "IsShape",
+						Package:   "main",
+						Proto: &FileProtoConfig{
+							Package:  "shapes.1v",
+							Filename: "dir/shape.txt",
+						},
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle":    {ProtoNumber: 19000},
+							"Rectangle": {ProtoNumber: 2},
+							"Square":    {ProtoNumber: 2},
+						},
+					},
+					{
+						Type:      "Shape2",
+						Interface: "IsShape",
+						Package:   "main",
+						Subtypes: map[string]FileSubtypeConfig{
+							"Circle": {ProtoNumber: 1},
+						},
+					},
+				},
+			},
+			wantErr: []string{
+				"type 'Shape': proto package 'shapes.1v' is not a valid protobuf package name",
+				"type 'Shape': missing proto goPackage",
+				"type 'Shape': proto filename 'dir/shape.txt' is not a .proto file name",
+				"type 'Shape': subtype 'Circle' has protoNumber 19000 which is not a valid protobuf field number",
+				"type 'Shape': subtype 'Square' has protoNumber 2 which is already used by subtype 'Rectangle'",
+				"type 'Shape2': subtype 'Circle' has a protoNumber but the type has no proto config",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.config)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %q", tt.wantErr)
+			}
+
+			if got := strings.Split(err.Error(), "\n"); !reflect.DeepEqual(got, tt.wantErr) {
+				t.Errorf("Validate() error = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	config := &FileConfig{
+		Dir: "out",
+		Types: []FileTypeConfig{
+			{
+				Type:        "Shape",
+				Interface:   "IsShape",
+				Package:     "main",
+				JSONVersion: JSONVersionBoth,
+				YAML:        true,
+				Subtypes: map[string]FileSubtypeConfig{
+					"Circle": {},
+				},
+			},
+			{
+				Type:      "Item",
+				Interface: "IsItem",
+				Package:   "items",
+				Directory: "items",
+				XML:       XMLElement,
+				Subtypes: map[string]FileSubtypeConfig{
+					"Text": {},
+				},
+			},
+		},
+	}
+
+	files, err := Render(config)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	want := []string{
+		filepath.Join("out", "items", "item_polygen.go"),
+		filepath.Join("out", "items", "item_polygen_xml.go"),
+		filepath.Join("out", "shape_polygen.go"),
+		filepath.Join("out", "shape_polygen_jsonv2.go"),
+		filepath.Join("out", "shape_polygen_yaml.go"),
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Render() paths = %v, want %v", paths, want)
+	}
+
+	if code := files[filepath.Join("out", "shape_polygen_jsonv2.go")]; !strings.Contains(string(code), "MarshalJSONTo") {
+		t.Errorf("Render() jsonv2 file does not contain jsonv2 code:\n%s", code)
+	}
+
+	if code := files[filepath.Join("out", "items", "item_polygen.go")]; !strings.Contains(string(code), "package items") {
+		t.Errorf("Render() item file has wrong package:\n%s", code)
+	}
+
+	if code := files[filepath.Join("out", "shape_polygen_yaml.go")]; !strings.Contains(string(code), "UnmarshalYAML") {
+		t.Errorf("Render() yaml file does not contain yaml code:\n%s", code)
+	}
+
+	if code := files[filepath.Join("out", "items", "item_polygen_xml.go")]; !strings.Contains(string(code), "UnmarshalXML") {
+		t.Errorf("Render() xml file does not contain xml code:\n%s", code)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string][]byte{
+		filepath.Join(tempDir, "a_polygen.go"):        []byte(GeneratedHeader + "\npackage a\n"),
+		filepath.Join(tempDir, "b", "b_polygen.go"):   []byte(GeneratedHeader + "\npackage b\n"),
+		filepath.Join(tempDir, "c", "c_polygen.go"):   []byte(GeneratedHeader + "\npackage c\n"),
+		filepath.Join(tempDir, "c", "c_unchanged.go"): []byte(GeneratedHeader + "\npackage c\n"),
+	}
+
+	if err := os.MkdirAll(filepath.Join(tempDir, "c"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestFile(t, filepath.Join(tempDir, "c", "c_unchanged.go"), GeneratedHeader+"\npackage c\n")
+
+	written, err := Write(files)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if written != 3 {
+		t.Errorf("Write() = %d, want 3", written)
+	}
+
+	for path, code := range files {
+		status, err := FileStatus(path, code)
+		if err != nil {
+			t.Fatalf("FileStatus() error = %v", err)
+		}
+
+		if status != StatusUnchanged {
+			t.Errorf("FileStatus(%s) = %s, want %s", path, status, StatusUnchanged)
+		}
+	}
+
+	written, err = Write(files)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if written != 0 {
+		t.Errorf("Write() = %d, want 0 on the second run", written)
+	}
+}
+
+func TestWrite_keepsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a_polygen.go")
+
+	writeTestFile(t, path, GeneratedHeader+"\npackage a\n")
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Write(map[string][]byte{path: []byte(GeneratedHeader + "\npackage b\n")}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("mode = %o, want %o", mode, 0o600)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, dir := range []string{"pkg", ".hidden", "vendor"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kept := filepath.Join(tempDir, "kept_polygen.go")
+	orphan := filepath.Join(tempDir, "pkg", "orphan_polygen.go")
+	orphanProto := filepath.Join(tempDir, "pkg", "orphan_polygen.proto")
+
+	writeTestFile(t, kept, GeneratedHeader+"\npackage a\n")
+	writeTestFile(t, orphan, GeneratedHeader+"\npackage pkg\n")
+	writeTestFile(t, orphanProto, GeneratedHeader+"\n\nsyntax = \"proto3\";\n")
+	writeTestFile(t, filepath.Join(tempDir, "pkg", "handwritten.proto"), "syntax = \"proto3\";\n")
+	writeTestFile(t, filepath.Join(tempDir, "pkg", "handwritten.go"), "package pkg\n")
+	writeTestFile(t, filepath.Join(tempDir, ".hidden", "hidden_polygen.go"), GeneratedHeader+"\n")
+	writeTestFile(t, filepath.Join(tempDir, "vendor", "vendor_polygen.go"), GeneratedHeader+"\n")
+
+	got, err := Orphans(tempDir, ".polygen.json", map[string][]byte{kept: nil})
+	if err != nil {
+		t.Fatalf("Orphans() error = %v", err)
+	}
+
+	if want := []string{orphan, orphanProto}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Orphans() = %v, want %v", got, want)
+	}
+}
+
+func TestOrphans_nestedConfigAndOutsideDirs(t *testing.T) {
+	tempDir := t.TempDir()
+	dir := filepath.Join(tempDir, "root")
+	nested := filepath.Join(dir, "nested")
+	outside := filepath.Join(tempDir, "outside")
+
+	for _, d := range []string{nested, filepath.Join(outside, "sub")} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeTestFile(t, filepath.Join(nested, ".polygen.json"), "{}\n")
+	writeTestFile(t, filepath.Join(nested, "nested_polygen.go"), GeneratedHeader+"\npackage nested\n")
+
+	kept := filepath.Join(outside, "kept_polygen.go")
+	orphan := filepath.Join(outside, "orphan_polygen.go")
+
+	writeTestFile(t, kept, GeneratedHeader+"\npackage outside\n")
+	writeTestFile(t, orphan, GeneratedHeader+"\npackage outside\n")
+	writeTestFile(t, filepath.Join(outside, "sub", "sub_polygen.go"), GeneratedHeader+"\npackage sub\n")
+
+	got, err := Orphans(dir, ".polygen.json", map[string][]byte{kept: nil})
+	if err != nil {
+		t.Fatalf("Orphans() error = %v", err)
+	}
+
+	if want := []string{orphan}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Orphans() = %v, want %v", got, want)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to create file %s: %v", path, err)
+	}
+}
+
+func TestRender_templates(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tempDir, "v1.go.tmpl"), `// Code generated by polygen; DO NOT EDIT.
+
+package {{.Package}}
+
+type {{.Type}} struct {
+	{{.Interface}}
+}
+`)
+	writeTestFile(t, filepath.Join(tempDir, "names.go.tmpl"), `// Code generated by polygen; DO NOT EDIT.
+
+package {{.Package}}
+
+{{imports "strings" "fmt" "strings"}}
+
+var _ = fmt.Sprint
+var _ = strings.ToUpper
+
+func ({{.Type}}) {{lowerFirst .Type}}Names() []string {
+	return []string{
+	{{- range .Types}}
+		{{quote (snake .SubType)}},
+	{{- end}}
+	}
+}
+`)
+	writeTestFile(t, filepath.Join(tempDir, "README.md.tmpl"), "# {{.Type}}\n")
+
+	config := &FileConfig{
+		Dir: tempDir,
+		Types: []FileTypeConfig{
+			{
+				Type:      "Shape",
+				Interface: "IsShape",
+				Package:   "main",
+				Subtypes: map[string]FileSubtypeConfig{
+					"Circle":      {},
+					"RoundSquare": {},
+				},
+				Templates: []FileTemplateConfig{
+					{Path: "v1.go.tmpl", Replace: JSONVersionV1},
+					{Path: "names.go.tmpl", Filename: "shape_names.go"},
+					{Path: "README.md.tmpl", Filename: "SHAPE.md"},
+				},
+			},
+		},
+	}
+
+	if err := Validate(config); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	files, err := Render(config)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := map[string]string{
+		filepath.Join(tempDir, "shape_polygen.go"): `// Code generated by polygen; DO NOT EDIT.
+
+package main
+
+type Shape struct {
+	IsShape
+}
+`,
+		filepath.Join(tempDir, "shape_names.go"): `// Code generated by polygen; DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var _ = fmt.Sprint
+var _ = strings.ToUpper
+
+func (Shape) shapeNames() []string {
+	return []string{
+		"circle",
+		"round_square",
+	}
+}
+`,
+		filepath.Join(tempDir, "SHAPE.md"): "# Shape\n",
+	}
+
+	if len(files) != len(want) {
+		t.Errorf("Render() rendered %d files, want %d", len(files), len(want))
+	}
+
+	for path, code := range want {
+		if got := string(files[path]); got != code {
+			t.Errorf("Render() %s =\n%s\nwant:\n%s", path, got, code)
+		}
+	}
+
+	config.Types[0].Templates = []FileTemplateConfig{
+		{Path: "v1.go.tmpl", Replace: "v3"},
+		{Path: "names.go.tmpl"},
+	}
+
+	wantErr := "type 'Shape': template 'v1.go.tmpl' replaces unknown template 'v3'\n" +
+		"type 'Shape': template 'names.go.tmpl' needs either replace or filename"
+	if err := Validate(config); err == nil || err.Error() != wantErr {
+		t.Errorf("Validate() error = %v, want %q", err, wantErr)
+	}
+}
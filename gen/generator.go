@@ -0,0 +1,168 @@
+package gen
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed template.go.tmpl
+var codeTemplate string
+
+//go:embed template_jsonv2.go.tmpl
+var codeTemplateJSONV2 string
+
+//go:embed template_xml.go.tmpl
+var codeTemplateXML string
+
+//go:embed template_yaml.go.tmpl
+var codeTemplateYAML string
+
+//go:embed template_gob.go.tmpl
+var codeTemplateGob string
+
+//go:embed template_sql.go.tmpl
+var codeTemplateSQL string
+
+//go:embed template_cbor.go.tmpl
+var codeTemplateCBOR string
+
+//go:embed template_slog.go.tmpl
+var codeTemplateSlog string
+
+//go:embed template_equal.go.tmpl
+var codeTemplateEqual string
+
+//go:embed template_clone.go.tmpl
+var codeTemplateClone string
+
+//go:embed template_containers.go.tmpl
+var codeTemplateContainers string
+
+//go:embed template_decoder.go.tmpl
+var codeTemplateDecoder string
+
+//go:embed template_decoder_iter.go.tmpl
+var codeTemplateDecoderIter string
+
+//go:embed template_proto.proto.tmpl
+var protoTemplate string
+
+//go:embed template_proto.go.tmpl
+var codeTemplateProto string
+
+// TemplateFuncs returns the functions available to all templates, built-in and user-supplied:
+//
+//	kebab       converts PascalCase to kebab-case ("TextItem" -> "text-item")
+//	snake       converts PascalCase to snake_case ("TextItem" -> "text_item")
+//	lowerFirst  lowercases the first letter ("TextItem" -> "textItem")
+//	upperFirst  uppercases the first letter ("textItem" -> "TextItem")
+//	quote       quotes a string as a Go string literal
+//	imports     renders an import declaration of the given paths, deduplicated and sorted
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"kebab":      toKebabCase,
+		"snake":      toSnakeCase,
+		"lowerFirst": lowerFirst,
+		"upperFirst": upperFirst,
+		"quote":      strconv.Quote,
+		"imports":    renderImports,
+	}
+}
+
+// generateProto renders the .proto file of a type and the Go functions converting to and from its message.
+func generateProto(file *protoFile) (proto, code []byte, err error) {
+	proto, err = executeTemplate("proto", protoTemplate, file, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	code, err = executeTemplate("code", codeTemplateProto, file, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return proto, code, nil
+}
+
+// executeTemplate renders the template with cfg as its data, formatting the result as Go code if isGo is set.
+func executeTemplate(name, tmplStr string, cfg any, isGo bool) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs()).Parse(tmplStr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, cfg); err != nil {
+		return nil, fmt.Errorf("executing template: %v", err)
+	}
+
+	if !isGo {
+		return buf.Bytes(), nil
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting code: %v", err)
+	}
+
+	return formatted, nil
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+func renderImports(paths ...string) string {
+	unique := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		unique[path] = true
+	}
+
+	sorted := make([]string, 0, len(unique))
+	for path := range unique {
+		sorted = append(sorted, path)
+	}
+
+	sort.Strings(sorted)
+
+	switch len(sorted) {
+	case 0:
+		return ""
+	case 1:
+		return "import " + strconv.Quote(sorted[0])
+	}
+
+	var b strings.Builder
+
+	b.WriteString("import (\n")
+
+	for _, path := range sorted {
+		b.WriteString("\t" + strconv.Quote(path) + "\n")
+	}
+
+	b.WriteString(")")
+
+	return b.String()
+}
@@ -0,0 +1,670 @@
+package gen
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("default values", func(t *testing.T) {
+		isPointerTrue := true
+		config := FileConfig{
+			Types: []FileTypeConfig{
+				{
+					Type:      "TestType",
+					Interface: "TestInterface",
+					Package:   "test",
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {},
+						"SubType2": {
+							Pointer: &isPointerTrue,
+						},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+		// Generate code
+		code, err := executeTemplate("code", codeTemplate, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		if code == nil {
+			t.Error("generated code is empty")
+		}
+
+		// Test required components
+		required := []string{
+			"package test",
+			"type TestType struct {",
+			"TestInterface",
+			"func (v TestType) MarshalJSON() ([]byte, error)",
+			"func (v *TestType) UnmarshalJSON(data []byte) error",
+			`"type":"`,
+			`case "sub-type-1":`,
+			`case "sub-type-2":`,
+			`reflect.TypeOf((*SubType1)(nil)).Elem():`,
+			`reflect.TypeOf((*SubType2)(nil)):`,
+		}
+
+		for _, r := range required {
+			if !bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code missing required part: %q", r)
+				t.Logf("Generated code:\n%s", string(code))
+			}
+		}
+	})
+
+	t.Run("custom values", func(t *testing.T) {
+		subType1Name := "my-subtype-1"
+		config := FileConfig{
+			DefaultDiscriminator: "kind",
+			StrictByDefault:      true,
+			PointerByDefault:     true,
+			DefaultBuildTag:      "darwin",
+			Types: []FileTypeConfig{
+				{
+					Type:      "TestType",
+					Interface: "TestInterface",
+					Package:   "test",
+					Directory: "pkg",
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {
+							Name: &subType1Name,
+						},
+						"SubType2": {},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+		// Generate code
+		code, err := executeTemplate("code", codeTemplate, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		if code == nil {
+			t.Error("generated code is empty")
+		}
+
+		// Test required components
+		required := []string{
+			"\n//go:build darwin\n",
+			"package test",
+			"type TestType struct {",
+			"TestInterface",
+			"func (v TestType) MarshalJSON() ([]byte, error)",
+			"func (v *TestType) UnmarshalJSON(data []byte) error",
+			`"kind":"`,
+			`case "my-subtype-1":`,
+			`case "sub-type-2":`,
+			`reflect.TypeOf((*SubType1)(nil)):`,
+			`reflect.TypeOf((*SubType2)(nil)):`,
+			"decoder.DisallowUnknownFields()",
+		}
+
+		for _, r := range required {
+			if !bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code missing required part: %q", r)
+				t.Logf("Generated code:\n%s", string(code))
+			}
+		}
+	})
+
+	t.Run("only jsonv2 values", func(t *testing.T) {
+		isPointerTrue := true
+		config := FileConfig{
+			Types: []FileTypeConfig{
+				{
+					Type:      "TestType",
+					Interface: "TestInterface",
+					Package:   "test",
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {},
+						"SubType2": {
+							Pointer: &isPointerTrue,
+						},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+		cfg.JSONVersion = "v2"
+
+		// Generate code
+		code, err := executeTemplate("code", codeTemplateJSONV2, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		if code == nil {
+			t.Error("generated code is empty")
+		}
+
+		// Test required components
+		required := []string{
+			"package test",
+			"type TestType struct {",
+			"TestInterface",
+			"func (v TestType) MarshalJSONTo(enc *jsontext.Encoder) error",
+			"func (v *TestType) UnmarshalJSONFrom(dec *jsontext.Decoder) error",
+			`"type":"`,
+			`case "sub-type-1":`,
+			`case "sub-type-2":`,
+			`reflect.TypeOf((*SubType1)(nil)).Elem():`,
+			`reflect.TypeOf((*SubType2)(nil)):`,
+		}
+
+		for _, r := range required {
+			if !bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code missing required part: %q", r)
+				t.Logf("Generated code:\n%s", string(code))
+			}
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		isPointerTrue := true
+		config := FileConfig{
+			Types: []FileTypeConfig{
+				{
+					Type:          "TestType",
+					Interface:     "TestInterface",
+					Package:       "test",
+					Discriminator: "kind",
+					XML:           XMLAttribute,
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {},
+						"SubType2": {
+							Pointer: &isPointerTrue,
+						},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+		// Generate code
+		code, err := executeTemplate("code", codeTemplateXML, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		// Test required components
+		required := []string{
+			"package test",
+			"func (v TestType) MarshalXML(e *xml.Encoder, start xml.StartElement) error",
+			"func (v *TestType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error",
+			`xml.Attr{Name: xml.Name{Local: "kind"}, Value: typeName}`,
+			`case "sub-type-1":`,
+			`case "sub-type-2":`,
+			"var vv *SubType2",
+		}
+
+		for _, r := range required {
+			if !bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code missing required part: %q", r)
+				t.Logf("Generated code:\n%s", string(code))
+			}
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		isPointerTrue, strictTrue := true, true
+		config := FileConfig{
+			Types: []FileTypeConfig{
+				{
+					Type:          "TestType",
+					Interface:     "TestInterface",
+					Package:       "test",
+					Discriminator: "meta.kind",
+					YAML:          true,
+					Strict:        &strictTrue,
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {},
+						"SubType2": {
+							Pointer: &isPointerTrue,
+						},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+		// Generate code
+		code, err := executeTemplate("code", codeTemplateYAML, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		// Test required components
+		required := []string{
+			"package test",
+			`"gopkg.in/yaml.v3"`,
+			"func (v TestType) MarshalYAML() (any, error)",
+			"func (v *TestType) UnmarshalYAML(node *yaml.Node) error",
+			`_TestTypeExtractYAMLDiscriminator(node, []string{"meta", "kind"}, &typeName)`,
+			`case "sub-type-1":`,
+			`case "sub-type-2":`,
+			"decoder.KnownFields(true)",
+		}
+
+		for _, r := range required {
+			if !bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code missing required part: %q", r)
+				t.Logf("Generated code:\n%s", string(code))
+			}
+		}
+	})
+
+	t.Run("gob", func(t *testing.T) {
+		isPointerTrue := true
+		config := FileConfig{
+			Types: []FileTypeConfig{
+				{
+					Type:      "TestType",
+					Interface: "TestInterface",
+					Package:   "test",
+					Gob:       true,
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {},
+						"SubType2": {
+							Pointer: &isPointerTrue,
+						},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+		// Generate code
+		code, err := executeTemplate("code", codeTemplateGob, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		// Test required components
+		required := []string{
+			"package test",
+			"func RegisterTestTypeGob() {",
+			`gob.RegisterName("test.sub-type-1", SubType1{})`,
+			`gob.RegisterName("test.sub-type-2", (*SubType2)(nil))`,
+			"func (v TestType) GobEncode() ([]byte, error)",
+			"func (v *TestType) GobDecode(data []byte) error",
+			"vv := new(SubType2)",
+		}
+
+		for _, r := range required {
+			if !bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code missing required part: %q", r)
+				t.Logf("Generated code:\n%s", string(code))
+			}
+		}
+	})
+
+	t.Run("sql", func(t *testing.T) {
+		config := FileConfig{
+			Types: []FileTypeConfig{
+				{
+					Type:        "TestType",
+					Interface:   "TestInterface",
+					Package:     "test",
+					SQL:         true,
+					JSONVersion: JSONVersionV2,
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+		// Generate code
+		code, err := executeTemplate("code", codeTemplateSQL, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		// Test required components
+		required := []string{
+			"package test",
+			"//go:build go1.25 && goexperiment.jsonv2",
+			`"encoding/json/v2"`,
+			"func (v *TestType) Scan(src any) error",
+			"func (v TestType) Value() (driver.Value, error)",
+		}
+
+		for _, r := range required {
+			if !bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code missing required part: %q", r)
+				t.Logf("Generated code:\n%s", string(code))
+			}
+		}
+	})
+
+	t.Run("cbor", func(t *testing.T) {
+		isPointerTrue := true
+		isStrict := true
+
+		config := FileConfig{
+			Types: []FileTypeConfig{
+				{
+					Type:          "TestType",
+					Interface:     "TestInterface",
+					Package:       "test",
+					Discriminator: "meta.kind",
+					Strict:        &isStrict,
+					CBOR:          true,
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {},
+						"SubType2": {Pointer: &isPointerTrue},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+		// Generate code
+		code, err := executeTemplate("code", codeTemplateCBOR, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		// Test required components
+		required := []string{
+			"package test",
+			`"github.com/ykalchevskiy/polygen/cbor"`,
+			"func (v TestType) MarshalCBOR() ([]byte, error)",
+			"func (v *TestType) UnmarshalCBOR(data []byte) error",
+			`cbor.InsertPath(implData, []string{"meta", "kind"}, typeNameData)`,
+			`cbor.ExtractPath(data, []string{"meta", "kind"})`,
+			"cbor.UnmarshalStrict(data, &vv)",
+		}
+
+		for _, r := range required {
+			if !bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code missing required part: %q", r)
+				t.Logf("Generated code:\n%s", string(code))
+			}
+		}
+	})
+
+	t.Run("slog", func(t *testing.T) {
+		for _, tt := range []struct {
+			jsonVersion string
+			buildTag    string
+			want        string
+		}{
+			{jsonVersion: JSONVersionBoth, want: "//go:build go1.21"},
+			{jsonVersion: JSONVersionV1, buildTag: "linux", want: "//go:build linux && go1.21"},
+			{jsonVersion: JSONVersionV2, want: "//go:build go1.25 && goexperiment.jsonv2"},
+		} {
+			config := FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:          "TestType",
+						Interface:     "TestInterface",
+						Package:       "test",
+						Discriminator: "kind",
+						Slog:          true,
+						BuildTag:      tt.buildTag,
+						JSONVersion:   tt.jsonVersion,
+						Subtypes: map[string]FileSubtypeConfig{
+							"SubType1": {},
+						},
+					},
+				},
+			}
+
+			cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+			// Generate code
+			code, err := executeTemplate("code", codeTemplateSlog, cfg, true)
+			if err != nil {
+				t.Fatalf("executeTemplate failed: %v", err)
+			}
+
+			// Test required components
+			required := []string{
+				"package test",
+				tt.want + "\n",
+				`"log/slog"`,
+				"func (v TestType) LogValue() slog.Value",
+				`slog.Any("kind", typeName)`,
+				"func (v TestType) String() string",
+			}
+
+			for _, r := range required {
+				if !bytes.Contains(code, []byte(r)) {
+					t.Errorf("generated code missing required part: %q", r)
+					t.Logf("Generated code:\n%s", string(code))
+				}
+			}
+		}
+	})
+
+	t.Run("equal and clone", func(t *testing.T) {
+		pointer := true
+
+		config := FileConfig{
+			Types: []FileTypeConfig{
+				{
+					Type:      "TestType",
+					Interface: "TestInterface",
+					Package:   "test",
+					Equal:     true,
+					Clone:     true,
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {},
+						"SubType2": {Pointer: &pointer},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+		// Generate code
+		equal, err := executeTemplate("code", codeTemplateEqual, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		clone, err := executeTemplate("code", codeTemplateClone, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		code := append(equal, clone...)
+
+		// Test required components
+		required := []string{
+			"func (v TestType) Equal(other TestType) bool",
+			"case SubType1:\n\t\treturn _TestTypeEqualSubType1(&vv, other.TestInterface)",
+			"case *SubType2:\n\t\treturn _TestTypeEqualSubType2(vv, other.TestInterface)",
+			"any(*v).(interface{ Equal(SubType1) bool })",
+			"func (v TestType) Clone() TestType",
+			"case SubType1:\n\t\treturn TestType{TestInterface: *_TestTypeCloneSubType1(&vv)}",
+			"case interface{ DeepCopy() *SubType2 }:",
+			"func _TestTypeDeepCopy(v reflect.Value) reflect.Value",
+		}
+
+		for _, r := range required {
+			if !bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code missing required part: %q", r)
+				t.Logf("Generated code:\n%s", string(code))
+			}
+		}
+
+		// A pointer subtype does not implement the interface as a value
+		for _, r := range []string{"case SubType2:", "o = &oo\n\tcase *SubType2:"} {
+			if bytes.Contains(code, []byte(r)) {
+				t.Errorf("generated code has unexpected part: %q", r)
+			}
+		}
+	})
+
+	t.Run("containers", func(t *testing.T) {
+		pointer := true
+
+		for _, tt := range []struct {
+			jsonVersion string
+			want        []string
+		}{
+			{
+				jsonVersion: JSONVersionBoth,
+				want: []string{
+					`"encoding/json"`,
+					"func (l *TestTypeList) UnmarshalJSON(data []byte) error",
+					"func (m *TestTypeMap) UnmarshalJSON(data []byte) error",
+				},
+			},
+			{
+				jsonVersion: JSONVersionV2,
+				want: []string{
+					"//go:build go1.25 && goexperiment.jsonv2",
+					`"encoding/json/jsontext"`,
+					"func (l *TestTypeList) UnmarshalJSONFrom(dec *jsontext.Decoder) error",
+					"func (m *TestTypeMap) UnmarshalJSONFrom(dec *jsontext.Decoder) error",
+				},
+			},
+		} {
+			config := FileConfig{
+				Types: []FileTypeConfig{
+					{
+						Type:              "TestType",
+						Interface:         "TestInterface",
+						Package:           "test",
+						DiscriminatorType: "int",
+						Containers:        true,
+						JSONVersion:       tt.jsonVersion,
+						Subtypes: map[string]FileSubtypeConfig{
+							"SubType1": {},
+							"SubType2": {Pointer: &pointer},
+						},
+					},
+				},
+			}
+
+			cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+			// Generate code
+			code, err := executeTemplate("code", codeTemplateContainers, cfg, true)
+			if err != nil {
+				t.Fatalf("executeTemplate failed: %v", err)
+			}
+
+			// Test required components
+			required := append([]string{
+				"type TestTypeList []TestType",
+				"type TestTypeMap map[string]TestType",
+				"func (l TestTypeList) FilterSubType1() []SubType1",
+				"func (m TestTypeMap) FilterSubType2() map[string]*SubType2",
+				"func (l TestTypeList) CountBySubType1() int",
+				"func (m TestTypeMap) CountBySubType2() int",
+			}, tt.want...)
+
+			for _, r := range required {
+				if !bytes.Contains(code, []byte(r)) {
+					t.Errorf("generated code missing required part: %q", r)
+					t.Logf("Generated code:\n%s", string(code))
+				}
+			}
+		}
+	})
+
+	t.Run("array decoder", func(t *testing.T) {
+		strict := true
+
+		config := FileConfig{
+			Types: []FileTypeConfig{
+				{
+					Type:         "TestType",
+					Interface:    "TestInterface",
+					Package:      "test",
+					Strict:       &strict,
+					BuildTag:     "linux",
+					ArrayDecoder: true,
+					Subtypes: map[string]FileSubtypeConfig{
+						"SubType1": {},
+					},
+				},
+			},
+		}
+
+		cfg := convertFileConfigToConfig(&config.Types[0], &config)
+
+		// Generate code
+		decoder, err := executeTemplate("code", codeTemplateDecoder, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		iter, err := executeTemplate("code", codeTemplateDecoderIter, cfg, true)
+		if err != nil {
+			t.Fatalf("executeTemplate failed: %v", err)
+		}
+
+		// Test required components
+		for _, tt := range []struct {
+			code     []byte
+			required []string
+		}{
+			{
+				code: decoder,
+				required: []string{
+					"//go:build linux\n",
+					"func NewTestTypeArrayDecoder(r io.Reader) *TestTypeArrayDecoder",
+					"dec.DisallowUnknownFields()",
+					"func (d *TestTypeArrayDecoder) Next() bool",
+					"func (d *TestTypeArrayDecoder) Err() error",
+				},
+			},
+			{
+				code: iter,
+				required: []string{
+					"//go:build linux && go1.23\n",
+					"func (d *TestTypeArrayDecoder) All() iter.Seq2[TestType, error]",
+				},
+			},
+		} {
+			for _, r := range tt.required {
+				if !bytes.Contains(tt.code, []byte(r)) {
+					t.Errorf("generated code missing required part: %q", r)
+					t.Logf("Generated code:\n%s", string(tt.code))
+				}
+			}
+		}
+	})
+}
+
+// The normalizer of discriminator values is generated by both JSON templates, whose copies must not drift apart.
+// tests/shape_matched_test.go pins the generated normalizer to DiscriminatorMatchKey.
+func TestNormalizeTypeNameTemplates(t *testing.T) {
+	normalizer := func(text string) []byte {
+		start := bytes.Index([]byte(text), []byte("func _{{.Type}}NormalizeTypeName("))
+		if start < 0 {
+			t.Fatal("template has no normalizer")
+		}
+
+		end := bytes.Index([]byte(text[start:]), []byte("\n}\n"))
+
+		return []byte(text[start : start+end])
+	}
+
+	if v1, v2 := normalizer(codeTemplate), normalizer(codeTemplateJSONV2); !bytes.Equal(v1, v2) {
+		t.Errorf("normalizers differ:\n%s\n\n%s", v1, v2)
+	}
+}
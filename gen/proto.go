@@ -0,0 +1,613 @@
+package gen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/types"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// protoFile is the data of the protobuf templates of a type.
+type protoFile struct {
+	*Config
+	// ProtoPackage is the protobuf package of the messages
+	ProtoPackage string
+	// GoPackage is the import path of the Go code protoc-gen-go generates from the .proto file
+	GoPackage string
+	// Oneof is the name of the oneof of the wrapper message and OneofGoName the Go name of its field
+	Oneof       string
+	OneofGoName string
+	// Cases are the fields of the oneof of the wrapper message, one per subtype
+	Cases []protoCase
+	// Messages are the messages of the subtypes and of the structs their fields hold
+	Messages []*protoMessage
+	// Imports are the packages of the Go types the conversion functions refer to
+	Imports []string
+}
+
+// protoCase is a field of the oneof of the wrapper message.
+type protoCase struct {
+	TypeMapping
+	// Name is the protobuf field name
+	Name   string
+	Number int
+	// Message is the message of the subtype
+	Message *protoMessage
+	// GoName is the Go name protoc-gen-go gives the field in its Wrapper type
+	GoName string
+	// Wrapper is the Go type protoc-gen-go generates for the field of the oneof
+	Wrapper string
+}
+
+// protoMessage is a message mirroring a Go struct type.
+type protoMessage struct {
+	Name string
+	// GoName is the Go type protoc-gen-go generates for the message
+	GoName string
+	// GoType is the Go type expression of the struct
+	GoType string
+	Fields []protoField
+}
+
+// protoField is a field of a message, copied by Go statements between the struct v and the message m.
+type protoField struct {
+	Name   string
+	Number int
+	// Type is the protobuf type, including the repeated or optional label
+	Type      string
+	ToProto   string
+	FromProto string
+}
+
+// protoValue is how values of a Go type map to a protobuf type.
+type protoValue struct {
+	// Type is the protobuf type
+	Type string
+	// IsMessage reports whether the type is a message, which protoc-gen-go holds by pointer
+	IsMessage bool
+	// PBType is the Go type protoc-gen-go uses for the values
+	PBType string
+	// ToProto and FromProto return the Go expressions converting the value of the expression x
+	ToProto   func(x string) string
+	FromProto func(x string) string
+}
+
+// protoOneof is the name of the oneof of the wrapper message.
+const protoOneof = "subtype"
+
+// protoReservedNames are the methods of protoc-gen-go messages, which fields named alike get an underscore appended for.
+var protoReservedNames = map[string]bool{
+	"Reset":        true,
+	"String":       true,
+	"ProtoMessage": true,
+	"ProtoReflect": true,
+	"Descriptor":   true,
+}
+
+// protoLock holds the field numbers given to the fields of the messages of a .proto file, by message and field name.
+// It is kept in a file next to the .proto file, so that numbers stay the same when fields are added, removed
+// or reordered. Numbers of removed fields and messages stay in it, so that they are never given out again.
+type protoLock struct {
+	Messages map[string]map[string]int `json:"messages"`
+}
+
+// readProtoLock reads the lock file at path, returning an empty lock if there is none yet.
+func readProtoLock(path string) (*protoLock, error) {
+	lock := &protoLock{}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading protobuf lock file: %v", err)
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(data, lock); err != nil {
+			return nil, fmt.Errorf("parsing protobuf lock file '%s': %v", path, err)
+		}
+	}
+
+	if lock.Messages == nil {
+		lock.Messages = make(map[string]map[string]int)
+	}
+
+	return lock, nil
+}
+
+// number returns the number of the field name of message. A field not in the lock yet is given
+// the lowest number not locked for the message, leaving out the range reserved by protobuf.
+func (l *protoLock) number(message, name string) int {
+	numbers := l.fields(message)
+
+	if number, ok := numbers[name]; ok {
+		return number
+	}
+
+	used := make(map[int]bool, len(numbers))
+	for _, number := range numbers {
+		used[number] = true
+	}
+
+	number := 1
+	for used[number] || number >= 19000 && number <= 19999 {
+		number++
+	}
+
+	numbers[name] = number
+
+	return number
+}
+
+// pin locks the field name of message to number, which must not be locked for another field of the message.
+func (l *protoLock) pin(message, name string, number int) error {
+	numbers := l.fields(message)
+
+	for other, n := range numbers {
+		if n == number && other != name {
+			return fmt.Errorf("number %d of field '%s' is locked for field '%s' of message '%s'", number, name, other, message)
+		}
+	}
+
+	numbers[name] = number
+
+	return nil
+}
+
+func (l *protoLock) fields(message string) map[string]int {
+	numbers, ok := l.Messages[message]
+	if !ok {
+		numbers = make(map[string]int)
+		l.Messages[message] = numbers
+	}
+
+	return numbers
+}
+
+// newProtoFile maps the subtypes of cfg, looked up in pkg, and the structs their fields hold to protobuf messages,
+// numbering their fields by lock, to which the fields new to it are added.
+// Fields of types without a protobuf mapping, such as interfaces or structs of other packages, are reported.
+func newProtoFile(cfg *Config, proto *FileProtoConfig, pkg *types.Package, lock *protoLock) (*protoFile, error) {
+	b := &protoBuilder{
+		pkg:     pkg,
+		prefix:  "_" + cfg.Type,
+		lock:    lock,
+		byType:  make(map[string]*protoMessage),
+		byName:  map[string]string{cfg.Type: cfg.Type},
+		imports: make(map[string]bool),
+	}
+
+	file := &protoFile{
+		Config:       cfg,
+		ProtoPackage: proto.Package,
+		GoPackage:    proto.GoPackage,
+		Oneof:        protoOneof,
+		OneofGoName:  protoGoCamelCase(protoOneof),
+	}
+
+	var errs []error
+
+	// Pinned numbers go first, so that they are not given to the other subtypes
+	for _, mapping := range cfg.Types {
+		if mapping.ProtoNumber != 0 {
+			if err := lock.pin(cfg.Type, toSnakeCase(mapping.SubType), mapping.ProtoNumber); err != nil {
+				errs = append(errs, fmt.Errorf("subtype '%s' has protoNumber %d: %v", mapping.SubType, mapping.ProtoNumber, err))
+			}
+		}
+	}
+
+	for _, mapping := range cfg.Types {
+		obj, ok := pkg.Scope().Lookup(mapping.SubType).(*types.TypeName)
+		if !ok {
+			errs = append(errs, fmt.Errorf("cannot find subtype '%s' in package '%s'", mapping.SubType, pkg.Path()))
+
+			continue
+		}
+
+		if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+			errs = append(errs, fmt.Errorf("subtype '%s' is not a struct", mapping.SubType))
+
+			continue
+		}
+
+		message, err := b.message(obj.Type(), mapping.SubType)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		name := toSnakeCase(mapping.SubType)
+		goName := protoGoName(name)
+
+		file.Cases = append(file.Cases, protoCase{
+			TypeMapping: mapping,
+			Name:        name,
+			Number:      lock.number(cfg.Type, name),
+			Message:     message,
+			GoName:      goName,
+			Wrapper:     protoGoCamelCase(cfg.Type) + "_" + goName,
+		})
+	}
+
+	errs = append(errs, b.errs...)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	file.Messages = b.messages
+
+	for path := range b.imports {
+		file.Imports = append(file.Imports, path)
+	}
+
+	sort.Strings(file.Imports)
+
+	return file, nil
+}
+
+type protoBuilder struct {
+	pkg *types.Package
+	// prefix starts the names of the conversion functions
+	prefix string
+	// lock numbers the fields of the messages
+	lock     *protoLock
+	messages []*protoMessage
+	// byType maps Go type expressions to their messages, byName message names to their Go type expressions
+	byType  map[string]*protoMessage
+	byName  map[string]string
+	imports map[string]bool
+	errs    []error
+}
+
+// typeString returns the Go expression of t in the package of the subtypes, recording the packages it refers to.
+func (b *protoBuilder) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == b.pkg {
+			return ""
+		}
+
+		b.imports[p.Path()] = true
+
+		return p.Name()
+	})
+}
+
+// message returns the message of the struct type t, named name unless it already has one.
+// Problems with the fields are collected in b.errs, so that all of them are reported at once.
+func (b *protoBuilder) message(t types.Type, name string) (*protoMessage, error) {
+	goType := b.typeString(t)
+
+	if message, ok := b.byType[goType]; ok {
+		return message, nil
+	}
+
+	if other, ok := b.byName[name]; ok {
+		return nil, fmt.Errorf("message '%s' of %s is already used for %s", name, goType, other)
+	}
+
+	message := &protoMessage{Name: name, GoName: protoGoCamelCase(name), GoType: goType}
+
+	// Register the message first, so that recursive types refer to it
+	b.byType[goType] = message
+	b.byName[name] = goType
+	b.messages = append(b.messages, message)
+
+	names := make(map[string]string)
+
+	for _, f := range protoStructFields(t.Underlying().(*types.Struct), "", nil) {
+		if f.embeddedPointer {
+			b.errs = append(b.errs, fmt.Errorf("field '%s' of %s is an embedded pointer, which has no protobuf mapping", f.path, goType))
+
+			continue
+		}
+
+		protoName := toSnakeCase(f.name)
+		if other, ok := names[protoName]; ok {
+			b.errs = append(b.errs, fmt.Errorf("fields '%s' and '%s' of %s have the same protobuf name '%s'", other, f.path, goType, protoName))
+
+			continue
+		}
+
+		names[protoName] = f.path
+
+		field, err := b.field(f.typ, f.path, protoName, name+f.name)
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("field '%s' of %s: %v", f.path, goType, err))
+
+			continue
+		}
+
+		field.Number = b.lock.number(name, protoName)
+		message.Fields = append(message.Fields, field)
+	}
+
+	return message, nil
+}
+
+// field maps a struct field of type t at the selector path to the protobuf field name.
+// Structs without a name get messages named anonName.
+func (b *protoBuilder) field(t types.Type, path, name, anonName string) (protoField, error) {
+	goName := protoGoName(name)
+	v, m := "v."+path, "m."+goName
+
+	field := protoField{Name: name}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		if elem, ok := u.Elem().Underlying().(*types.Slice); ok && isProtoBytes(elem) {
+			return field, fmt.Errorf("pointer to bytes %s has no protobuf mapping", b.typeString(t))
+		}
+
+		value, err := b.value(u.Elem(), anonName)
+		if err != nil {
+			return field, err
+		}
+
+		if value.IsMessage {
+			field.Type = value.Type
+			field.ToProto = fmt.Sprintf("if %s != nil {\n%s = %s\n}", v, m, value.ToProto("*"+v))
+			field.FromProto = fmt.Sprintf("if %s != nil {\nx := %s\n%s = &x\n}", m, value.FromProto(m), v)
+		} else {
+			field.Type = "optional " + value.Type
+			field.ToProto = fmt.Sprintf("if %s != nil {\nx := %s\n%s = &x\n}", v, value.ToProto("*"+v), m)
+			field.FromProto = fmt.Sprintf("if %s != nil {\nx := %s\n%s = &x\n}", m, value.FromProto("*"+m), v)
+		}
+
+		return field, nil
+	case *types.Slice:
+		if isProtoBytes(u) {
+			break
+		}
+
+		value, err := b.value(u.Elem(), anonName)
+		if err != nil {
+			return field, err
+		}
+
+		field.Type = "repeated " + value.Type
+		field.ToProto = fmt.Sprintf("if %s != nil {\n%s = make([]%s, len(%s))\nfor i, e := range %s {\n%s[i] = %s\n}\n}",
+			v, m, value.PBType, v, v, m, value.ToProto("e"))
+		field.FromProto = fmt.Sprintf("if %s != nil {\n%s = make(%s, len(%s))\nfor i, e := range %s {\n%s[i] = %s\n}\n}",
+			m, v, b.typeString(t), m, m, v, value.FromProto("e"))
+
+		return field, nil
+	case *types.Map:
+		if basic, ok := u.Key().Underlying().(*types.Basic); !ok || basic.Info()&(types.IsInteger|types.IsString|types.IsBoolean) == 0 {
+			return field, fmt.Errorf("map key %s has no protobuf mapping", b.typeString(u.Key()))
+		}
+
+		key, err := b.value(u.Key(), anonName)
+		if err != nil {
+			return field, err
+		}
+
+		value, err := b.value(u.Elem(), anonName)
+		if err != nil {
+			return field, err
+		}
+
+		field.Type = fmt.Sprintf("map<%s, %s>", key.Type, value.Type)
+		field.ToProto = fmt.Sprintf("if %s != nil {\n%s = make(map[%s]%s, len(%s))\nfor k, e := range %s {\n%s[%s] = %s\n}\n}",
+			v, m, key.PBType, value.PBType, v, v, m, key.ToProto("k"), value.ToProto("e"))
+		field.FromProto = fmt.Sprintf("if %s != nil {\n%s = make(%s, len(%s))\nfor k, e := range %s {\n%s[%s] = %s\n}\n}",
+			m, v, b.typeString(t), m, m, v, key.FromProto("k"), value.FromProto("e"))
+
+		return field, nil
+	}
+
+	value, err := b.value(t, anonName)
+	if err != nil {
+		return field, err
+	}
+
+	field.Type = value.Type
+	field.ToProto = fmt.Sprintf("%s = %s", m, value.ToProto(v))
+	field.FromProto = fmt.Sprintf("%s = %s", v, value.FromProto(m))
+
+	return field, nil
+}
+
+// value maps a scalar, byte slice or struct type t to a protobuf type. Structs without a name get messages named anonName.
+func (b *protoBuilder) value(t types.Type, anonName string) (*protoValue, error) {
+	goType := b.typeString(t)
+
+	convert := func(protoType, pbType string) *protoValue {
+		return &protoValue{
+			Type:      protoType,
+			PBType:    pbType,
+			ToProto:   protoConversion(goType, pbType),
+			FromProto: protoConversion(pbType, goType),
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch u.Kind() {
+		case types.Bool:
+			return convert("bool", "bool"), nil
+		case types.String:
+			return convert("string", "string"), nil
+		case types.Int, types.Int64:
+			return convert("int64", "int64"), nil
+		case types.Int8, types.Int16, types.Int32:
+			return convert("int32", "int32"), nil
+		case types.Uint, types.Uint64:
+			return convert("uint64", "uint64"), nil
+		case types.Uint8, types.Uint16, types.Uint32:
+			return convert("uint32", "uint32"), nil
+		case types.Float32:
+			return convert("float", "float32"), nil
+		case types.Float64:
+			return convert("double", "float64"), nil
+		}
+	case *types.Slice:
+		if isProtoBytes(u) {
+			return convert("bytes", "[]byte"), nil
+		}
+	case *types.Struct:
+		name := anonName
+
+		if named, ok := t.(*types.Named); ok {
+			if named.Obj().Pkg() != b.pkg {
+				return nil, fmt.Errorf("struct %s of another package has no protobuf mapping", goType)
+			}
+
+			if named.TypeArgs().Len() > 0 {
+				return nil, fmt.Errorf("generic struct %s has no protobuf mapping", goType)
+			}
+
+			name = named.Obj().Name()
+		}
+
+		message, err := b.message(t, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &protoValue{
+			Type:      message.Name,
+			IsMessage: true,
+			PBType:    "*pb." + message.GoName,
+			ToProto:   func(x string) string { return b.prefix + "ToProto" + message.GoName + "(" + x + ")" },
+			FromProto: func(x string) string { return b.prefix + "FromProto" + message.GoName + "(" + x + ")" },
+		}, nil
+	}
+
+	return nil, fmt.Errorf("type %s has no protobuf mapping", goType)
+}
+
+// protoConversion returns the function converting an expression of type from to type to, only if they differ.
+func protoConversion(from, to string) func(x string) string {
+	if from == to {
+		return func(x string) string { return x }
+	}
+
+	return func(x string) string { return to + "(" + x + ")" }
+}
+
+// isProtoBytes reports whether the slice s is a []byte, which maps to bytes rather than a repeated field.
+func isProtoBytes(s *types.Slice) bool {
+	basic, ok := s.Elem().Underlying().(*types.Basic)
+
+	return ok && basic.Kind() == types.Uint8
+}
+
+// protoStructField is a struct field mapped to a message field, possibly promoted from embedded structs.
+type protoStructField struct {
+	name string
+	// path is the Go selector of the field from the struct
+	path            string
+	typ             types.Type
+	embeddedPointer bool
+}
+
+// protoStructFields returns the exported fields of s that encoding/json marshals, flattening embedded structs.
+func protoStructFields(s *types.Struct, prefix string, visited map[*types.Struct]bool) []protoStructField {
+	if visited == nil {
+		visited = make(map[*types.Struct]bool)
+	}
+
+	// Guard against embedding cycles
+	if visited[s] {
+		return nil
+	}
+
+	visited[s] = true
+	defer delete(visited, s)
+
+	var fields []protoStructField
+
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+
+		tag := reflect.StructTag(s.Tag(i)).Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Embedded() && name == "" {
+			if ptr, ok := field.Type().(*types.Pointer); ok {
+				if _, ok := ptr.Elem().Underlying().(*types.Struct); ok {
+					fields = append(fields, protoStructField{name: field.Name(), path: prefix + field.Name(), typ: field.Type(), embeddedPointer: true})
+
+					continue
+				}
+			}
+
+			if embedded, ok := field.Type().Underlying().(*types.Struct); ok {
+				fields = append(fields, protoStructFields(embedded, prefix+field.Name()+".", visited)...)
+
+				continue
+			}
+		}
+
+		if !field.Exported() {
+			continue
+		}
+
+		fields = append(fields, protoStructField{name: field.Name(), path: prefix + field.Name(), typ: field.Type()})
+	}
+
+	return fields
+}
+
+// protoGoName returns the Go name protoc-gen-go gives the field of a message with the protobuf name.
+func protoGoName(name string) string {
+	goName := protoGoCamelCase(name)
+	if protoReservedNames[goName] {
+		goName += "_"
+	}
+
+	return goName
+}
+
+// protoGoCamelCase converts a protobuf name to a Go identifier the way protoc-gen-go does:
+// underscores followed by a lowercase letter are dropped and the letter is uppercased, like the first one.
+func protoGoCamelCase(s string) string {
+	var b []byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skip over '.' in ".{{lowercase}}"
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			// Convert the initial '_' to 'X', so that the identifier is exported
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skip over '_' in "_{{lowercase}}"
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			// Assume we have a letter now, uppercase it and copy the lowercase letters following it
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+
+			b = append(b, c)
+
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+
+	return string(b)
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
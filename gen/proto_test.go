@@ -0,0 +1,281 @@
+package gen
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRender_proto(t *testing.T) {
+	tempDir := t.TempDir()
+	pointer := true
+
+	writeTestFile(t, filepath.Join(tempDir, "event.go"), `package event
+
+import "time"
+
+type IsEvent interface{ isEvent() }
+
+type Level int32
+
+type Meta struct {
+	ID   []byte
+	Tags map[string]string
+}
+
+type Created struct {
+	Meta
+	Name    string
+	Level   Level
+	Count   *uint64
+	Parent  *Meta
+	Counts  map[int]float32
+	Ignored string `+"`json:\"-\"`"+`
+}
+
+type Deleted struct {
+	Reset bool
+	Metas []Meta
+}
+
+type Broken struct {
+	At    time.Time
+	Ch    chan int
+	Keys  map[float64]bool
+	Bytes *[]byte
+}
+
+func (Created) isEvent()  {}
+func (*Deleted) isEvent() {}
+func (Broken) isEvent()   {}
+`)
+
+	config := &FileConfig{
+		Dir: tempDir,
+		Types: []FileTypeConfig{
+			{
+				Type:      "Event",
+				Interface: "IsEvent",
+				Package:   "event",
+				Proto: &FileProtoConfig{
+					Package:   "example.event",
+					GoPackage: "example.com/event/eventpb",
+					Filename:  "events.proto",
+				},
+				Subtypes: map[string]FileSubtypeConfig{
+					"Created": {ProtoNumber: 2},
+					"Deleted": {Pointer: &pointer},
+				},
+			},
+		},
+	}
+
+	files, err := Render(config)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	code := files[filepath.Join(tempDir, "event_polygen_proto.go")]
+	if code == nil {
+		t.Fatalf("Render() files = %v, want the proto conversions", fileNames(files))
+	}
+
+	for _, want := range []string{
+		`pb "example.com/event/eventpb"`,
+		"func EventToProto(v Event) (*pb.Event, error) {",
+		"func EventFromProto(m *pb.Event) (Event, error) {",
+		"m.Level = int32(v.Level)",
+		"v.Level = Level(m.Level)",
+		"m.Reset_ = v.Reset",
+		"m.Count = &x",
+	} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("Render() proto code does not contain %q:\n%s", want, code)
+		}
+	}
+
+	want := `// Code generated by polygen; DO NOT EDIT.
+
+syntax = "proto3";
+
+package example.event;
+
+option go_package = "example.com/event/eventpb";
+
+// Event holds one of the subtypes of IsEvent.
+message Event {
+  oneof subtype {
+    Created created = 2;
+    Deleted deleted = 1;
+  }
+}
+
+message Created {
+  bytes id = 1;
+  map<string, string> tags = 2;
+  string name = 3;
+  int32 level = 4;
+  optional uint64 count = 5;
+  Meta parent = 6;
+  map<int64, float> counts = 7;
+}
+
+message Meta {
+  bytes id = 1;
+  map<string, string> tags = 2;
+}
+
+message Deleted {
+  bool reset = 1;
+  repeated Meta metas = 2;
+}
+`
+	if got := string(files[filepath.Join(tempDir, "events.proto")]); got != want {
+		t.Errorf("Render() proto =\n%s\nwant\n%s", got, want)
+	}
+
+	config.Types[0].Subtypes["Broken"] = FileSubtypeConfig{}
+
+	_, err = Render(config)
+	if err == nil {
+		t.Fatal("Render() error = nil, want unsupported fields")
+	}
+
+	for _, want := range []string{
+		"field 'At' of Broken: struct time.Time of another package has no protobuf mapping",
+		"field 'Ch' of Broken: type chan int has no protobuf mapping",
+		"field 'Keys' of Broken: map key float64 has no protobuf mapping",
+		"field 'Bytes' of Broken: pointer to bytes *[]byte has no protobuf mapping",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Render() error = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestRender_protoLock(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tempDir, "event.go"), `package event
+
+type IsEvent interface{ isEvent() }
+
+type Created struct {
+	Name  string
+	Level int
+}
+
+type Deleted struct{}
+
+func (Created) isEvent() {}
+func (Deleted) isEvent() {}
+`)
+
+	config := &FileConfig{
+		Dir: tempDir,
+		Types: []FileTypeConfig{
+			{
+				Type:      "Event",
+				Interface: "IsEvent",
+				Package:   "event",
+				Proto: &FileProtoConfig{
+					Package:   "example.event",
+					GoPackage: "example.com/event/eventpb",
+				},
+				Subtypes: map[string]FileSubtypeConfig{
+					"Created": {},
+					"Deleted": {},
+				},
+			},
+		},
+	}
+
+	files, err := RenderProto(config)
+	if err != nil {
+		t.Fatalf("RenderProto() error = %v", err)
+	}
+
+	if want := []string{"event_polygen.proto", "event_polygen_proto.go", "event_polygen_proto.lock.json"}; !reflect.DeepEqual(sortedNames(files), want) {
+		t.Fatalf("RenderProto() files = %v, want %v", sortedNames(files), want)
+	}
+
+	if _, err := Write(files); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// Removing, reordering and adding fields and subtypes keeps the numbers of the others
+	writeTestFile(t, filepath.Join(tempDir, "event.go"), `package event
+
+type IsEvent interface{ isEvent() }
+
+type Created struct {
+	Added bool
+	Level int
+}
+
+type Archived struct{}
+
+func (Created) isEvent()  {}
+func (Archived) isEvent() {}
+`)
+
+	config.Types[0].Subtypes = map[string]FileSubtypeConfig{
+		"Created":  {},
+		"Archived": {},
+	}
+
+	files, err = RenderProto(config)
+	if err != nil {
+		t.Fatalf("RenderProto() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"    Archived archived = 3;\n    Created created = 1;\n",
+		"message Created {\n  bool added = 3;\n  int64 level = 2;\n}\n",
+	} {
+		if got := string(files[filepath.Join(tempDir, "event_polygen.proto")]); !strings.Contains(got, want) {
+			t.Errorf("RenderProto() proto =\n%s\nwant it to contain\n%s", got, want)
+		}
+	}
+
+	if _, err := Write(files); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// The number of the removed subtype is not given to another one, even if pinned
+	config.Types[0].Subtypes["Archived"] = FileSubtypeConfig{ProtoNumber: 2}
+
+	_, err = RenderProto(config)
+	if want := "number 2 of field 'archived' is locked for field 'deleted' of message 'Event'"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("RenderProto() error = %v, want %q", err, want)
+	}
+}
+
+func Test_protoGoCamelCase(t *testing.T) {
+	got := make([]string, 0)
+	for _, name := range []string{"radius", "fill_color", "x2_y", "_hidden", "a.b", "a.B", "already_Camel"} {
+		got = append(got, protoGoCamelCase(name))
+	}
+
+	if want := []string{"Radius", "FillColor", "X2Y", "XHidden", "AB", "A_B", "Already_Camel"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("protoGoCamelCase() = %q, want %q", got, want)
+	}
+}
+
+func sortedNames(files map[string][]byte) []string {
+	names := fileNames(files)
+	sort.Strings(names)
+
+	return names
+}
+
+func fileNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, filepath.Base(name))
+	}
+
+	return names
+}
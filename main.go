@@ -1,80 +1,217 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
+	"sort"
+
+	"github.com/ykalchevskiy/polygen/gen"
 )
 
+// runOptions holds command-line options affecting how the generator runs.
+type runOptions struct {
+	// Prune removes generated files that no longer belong to any configured type
+	Prune bool
+	// DryRun reports what would be written or removed without touching the filesystem
+	DryRun bool
+	// Stdout is the name of a type whose rendered code is printed instead of written
+	Stdout string
+	// Watch keeps running and regenerates affected types when the config or their packages change
+	Watch bool
+	// Proto generates just the protobuf files of the types with a proto config
+	Proto bool
+}
+
 func main() {
 	configPath := flag.String("config", ".polygen.json", "Path to the configuration file")
+	prune := flag.Bool("prune", false, "Remove previously generated files that no configured type maps to")
+	dryRun := flag.Bool("dry-run", false, "List files that would be created, changed or removed without writing them")
+	stdout := flag.String("stdout", "", "Print the generated code for the given type to standard output instead of writing it")
+	watchMode := flag.Bool("watch", false, "Watch the config file and the packages of configured types and regenerate on changes")
+	protoOnly := flag.Bool("proto", false, "Generate only the .proto files, conversions and field number locks of the types with a proto config")
 
 	flag.Parse()
 
-	if err := run(*configPath); err != nil {
+	opts := runOptions{
+		Prune:  *prune,
+		DryRun: *dryRun,
+		Stdout: *stdout,
+		Watch:  *watchMode,
+		Proto:  *protoOnly,
+	}
+
+	if err := run(*configPath, opts); err != nil {
 		log.Fatalf("Failed to generate: %v", err)
 	}
 }
 
-func run(configPath string) error {
-	configData, err := os.ReadFile(configPath)
-	if err != nil {
-		return fmt.Errorf("reading config file '%s': %v", configPath, err)
+func run(configPath string, opts runOptions) error {
+	if opts.Watch {
+		if opts.Stdout != "" {
+			return errors.New("-watch cannot be combined with -stdout")
+		}
+
+		return watch(configPath, opts, watchInterval, nil, nil)
 	}
 
-	var config FileConfig
-	if err := json.Unmarshal(configData, &config); err != nil {
-		return fmt.Errorf("parsing config file '%s': %v", configPath, err)
+	return generateFromConfig(configPath, opts, nil)
+}
+
+// generateFromConfig generates the code for the types of the config.
+// If only is not nil, just the types named in it are generated and pruning is skipped.
+func generateFromConfig(configPath string, opts runOptions, only map[string]bool) error {
+	config, err := gen.LoadConfig(configPath)
+	if err != nil {
+		return err
 	}
 
-	configDir := filepath.Dir(configPath)
+	if err := gen.Validate(config); err != nil {
+		return fmt.Errorf("invalid config file '%s': %v", configPath, err)
+	}
 
-	for _, typeConfig := range config.Types {
-		cfg := convertFileConfigToConfig(&typeConfig, &config)
+	if opts.Stdout != "" {
+		only = map[string]bool{opts.Stdout: true}
+	}
 
-		outputPath := getOutputPath(&typeConfig, configDir)
+	if only != nil {
+		var types []gen.FileTypeConfig
 
-		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
-			return fmt.Errorf("creating output directory '%s' for type '%s': %v", outputPath, typeConfig.Type, err)
+		for _, typeConfig := range config.Types {
+			if only[typeConfig.Type] {
+				types = append(types, typeConfig)
+			}
 		}
 
-		switch cfg.JSONVersion {
-		case JSONVersionBoth:
-			if err := generateAndWrite(cfg, generate, outputPath); err != nil {
-				return fmt.Errorf("v1: %v", err)
-			}
+		config.Types = types
+		opts.Prune = false
+	}
 
-			outputPathV2 := strings.TrimSuffix(outputPath, ".go") + "_jsonv2.go"
-			if err := generateAndWrite(cfg, generateJSONV2, outputPathV2); err != nil {
-				return fmt.Errorf("v2: %v", err)
+	if opts.Stdout != "" && len(config.Types) == 0 {
+		return fmt.Errorf("unknown type '%s'", opts.Stdout)
+	}
+
+	render := gen.Render
+
+	if opts.Proto {
+		var types []gen.FileTypeConfig
+
+		for _, typeConfig := range config.Types {
+			if typeConfig.Proto != nil {
+				types = append(types, typeConfig)
 			}
-		case JSONVersionV2:
-			if err := generateAndWrite(cfg, generateJSONV2, outputPath); err != nil {
-				return fmt.Errorf("v2: %v", err)
+		}
+
+		if len(types) == 0 {
+			return errors.New("no type with a proto config to generate")
+		}
+
+		config.Types = types
+		render = gen.RenderProto
+		opts.Prune = false
+	}
+
+	warnings, err := gen.CheckFields(config)
+	if err != nil {
+		return fmt.Errorf("invalid subtypes in config file '%s': %v", configPath, err)
+	}
+
+	for _, warning := range warnings {
+		log.Printf("Warning: %s", warning)
+	}
+
+	files, err := render(config)
+	if err != nil {
+		return err
+	}
+
+	if opts.Stdout != "" {
+		return printFiles(os.Stdout, files)
+	}
+
+	if opts.DryRun {
+		for _, path := range sortedPaths(files) {
+			status, err := gen.FileStatus(path, files[path])
+			if err != nil {
+				return err
 			}
-		default: // JSONVersionV1 or fallback
-			if err := generateAndWrite(cfg, generate, outputPath); err != nil {
-				return fmt.Errorf("v1: %v", err)
+
+			fmt.Printf("%-9s %s\n", status, path)
+		}
+	} else {
+		written, err := gen.Write(files)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("polygen: %d written, %d unchanged\n", written, len(files)-written)
+	}
+
+	if opts.Prune {
+		if err := prune(config.Dir, filepath.Base(configPath), files, opts.DryRun); err != nil {
+			return fmt.Errorf("pruning: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// printFiles writes the rendered code to w.
+// When there are several files, each one is preceded by a comment with its path.
+func printFiles(w io.Writer, files map[string][]byte) error {
+	for i, path := range sortedPaths(files) {
+		if len(files) > 1 {
+			if i > 0 {
+				fmt.Fprintln(w)
 			}
+
+			fmt.Fprintf(w, "// File: %s\n", path)
+		}
+
+		if _, err := w.Write(files[path]); err != nil {
+			return fmt.Errorf("printing generated code of '%s': %v", path, err)
 		}
 	}
 
 	return nil
 }
 
-func generateAndWrite(cfg *Config, gen func(*Config) ([]byte, error), outputPath string) error {
-	code, err := gen(cfg)
+// prune removes files under dir carrying the polygen header that are not among files.
+// With dryRun set, the files are only reported.
+func prune(dir, configName string, files map[string][]byte, dryRun bool) error {
+	orphans, err := gen.Orphans(dir, configName, files)
 	if err != nil {
-		return fmt.Errorf("generating code for type '%s': %v", cfg.Type, err)
+		return err
 	}
 
-	if err := os.WriteFile(outputPath, code, 0o644); err != nil {
-		return fmt.Errorf("writing generated code to '%s': %v", outputPath, err)
+	for _, path := range orphans {
+		if dryRun {
+			fmt.Printf("%-9s %s\n", "remove", path)
+
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("removing orphaned file '%s': %v", path, err)
+		}
+
+		log.Printf("Removed orphaned file %s", path)
 	}
 
 	return nil
 }
+
+func sortedPaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestMain(t *testing.T) {
@@ -230,6 +231,348 @@ func main() {
 	})
 }
 
+func TestMain_prune(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, ".polygen.json")
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"subtypes": {
+				"ItemValue1": {}
+			}
+		},
+		{
+			"type": "OtherValue",
+			"interface": "IsOtherValue",
+			"package": "pkg",
+			"directory": "other",
+			"subtypes": {
+				"OtherValue1": {}
+			}
+		}
+	]
+}`)
+
+	cmd := exec.Command("go", "run", ".", "-config", configFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generator failed: %v\nOutput: %s", err, output)
+	}
+
+	// Remove the second type and move the first one to a new file
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"filename": "item_value_gen.go",
+			"subtypes": {
+				"ItemValue1": {}
+			}
+		}
+	]
+}`)
+
+	// A hand-written file must never be pruned
+	handWritten := filepath.Join(tempDir, "other", "other_value.go")
+	createFile(t, handWritten, "package pkg\n")
+
+	cmd = exec.Command("go", "run", ".", "-config", configFile, "-prune")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generator failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, path := range []string{
+		filepath.Join(tempDir, "item_value_polygen.go"),
+		filepath.Join(tempDir, "other", "other_value_polygen.go"),
+	} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("orphaned file %s was not pruned: %v", path, err)
+		}
+	}
+
+	for _, path := range []string{
+		filepath.Join(tempDir, "item_value_gen.go"),
+		handWritten,
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("file %s must be kept: %v", path, err)
+		}
+	}
+}
+
+func TestMain_dryRun(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, ".polygen.json")
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"jsonVersion": "both",
+			"subtypes": {
+				"ItemValue1": {}
+			}
+		},
+		{
+			"type": "OtherValue",
+			"interface": "IsOtherValue",
+			"package": "pkg",
+			"subtypes": {
+				"OtherValue1": {}
+			}
+		}
+	]
+}`)
+
+	cmd := exec.Command("go", "run", ".", "-config", configFile, "-dry-run")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generator failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, want := range []string{
+		"create    " + filepath.Join(tempDir, "item_value_polygen.go"),
+		"create    " + filepath.Join(tempDir, "item_value_polygen_jsonv2.go"),
+		"create    " + filepath.Join(tempDir, "other_value_polygen.go"),
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("dry-run output missing %q\nOutput: %s", want, output)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "item_value_polygen.go")); !os.IsNotExist(err) {
+		t.Fatalf("dry-run must not write files: %v", err)
+	}
+
+	cmd = exec.Command("go", "run", ".", "-config", configFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generator failed: %v\nOutput: %s", err, output)
+	}
+
+	// Change one file by hand and drop the other type from the config
+	createFile(t, filepath.Join(tempDir, "item_value_polygen.go"), "// Code generated by polygen; DO NOT EDIT.\npackage pkg\n")
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"jsonVersion": "both",
+			"subtypes": {
+				"ItemValue1": {}
+			}
+		}
+	]
+}`)
+
+	cmd = exec.Command("go", "run", ".", "-config", configFile, "-dry-run", "-prune")
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generator failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, want := range []string{
+		"change    " + filepath.Join(tempDir, "item_value_polygen.go"),
+		"unchanged " + filepath.Join(tempDir, "item_value_polygen_jsonv2.go"),
+		"remove    " + filepath.Join(tempDir, "other_value_polygen.go"),
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("dry-run output missing %q\nOutput: %s", want, output)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "other_value_polygen.go")); err != nil {
+		t.Errorf("dry-run must not remove files: %v", err)
+	}
+}
+
+func TestMain_unchanged(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, ".polygen.json")
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"jsonVersion": "both",
+			"subtypes": {
+				"ItemValue1": {}
+			}
+		}
+	]
+}`)
+
+	cmd := exec.Command("go", "run", ".", "-config", configFile)
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("generator failed: %v\nOutput: %s", err, output)
+	}
+
+	if want := "polygen: 2 written, 0 unchanged\n"; string(output) != want {
+		t.Errorf("got output %q, want %q", output, want)
+	}
+
+	genFile := filepath.Join(tempDir, "item_value_polygen.go")
+
+	// Make the modification time distinguishable from a fresh write
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(genFile, past, past); err != nil {
+		t.Fatalf("failed to change file times: %v", err)
+	}
+
+	cmd = exec.Command("go", "run", ".", "-config", configFile)
+	output, err = cmd.Output()
+	if err != nil {
+		t.Fatalf("generator failed: %v\nOutput: %s", err, output)
+	}
+
+	if want := "polygen: 0 written, 2 unchanged\n"; string(output) != want {
+		t.Errorf("got output %q, want %q", output, want)
+	}
+
+	info, err := os.Stat(genFile)
+	if err != nil {
+		t.Fatalf("failed to stat generated file: %v", err)
+	}
+
+	if !info.ModTime().Equal(past) {
+		t.Errorf("unchanged file was rewritten: mtime %v, want %v", info.ModTime(), past)
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Errorf("temporary files must not be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestMain_stdout(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, ".polygen.json")
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"subtypes": {
+				"ItemValue1": {}
+			}
+		},
+		{
+			"type": "OtherValue",
+			"interface": "IsOtherValue",
+			"package": "pkg",
+			"subtypes": {
+				"OtherValue1": {}
+			}
+		}
+	]
+}`)
+
+	cmd := exec.Command("go", "run", ".", "-config", configFile, "-stdout", "OtherValue")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("generator failed: %v\nOutput: %s", err, output)
+	}
+
+	if !bytes.HasPrefix(output, []byte("// Code generated by polygen; DO NOT EDIT.")) {
+		t.Errorf("stdout output must start with the generated header\nOutput: %s", output)
+	}
+
+	if !bytes.Contains(output, []byte("type OtherValue struct {")) || bytes.Contains(output, []byte("type ItemValue struct {")) {
+		t.Errorf("stdout output must contain only the chosen type\nOutput: %s", output)
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("stdout mode must not write files, got %d entries", len(entries))
+	}
+
+	cmd = exec.Command("go", "run", ".", "-config", configFile, "-stdout", "MissingValue")
+	if output, err := cmd.CombinedOutput(); err == nil {
+		t.Errorf("expected an error for an unknown type\nOutput: %s", output)
+	}
+}
+
+func TestMain_proto(t *testing.T) {
+	tempDir := t.TempDir()
+
+	createFile(t, filepath.Join(tempDir, "item.go"), `package pkg
+
+type IsItemValue interface{ isItemValue() }
+
+type ItemValue1 struct {
+	Name string
+}
+
+func (ItemValue1) isItemValue() {}
+`)
+
+	configFile := filepath.Join(tempDir, ".polygen.json")
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"proto": {
+				"package": "pkg",
+				"goPackage": "example.com/pkg/pkgpb"
+			},
+			"subtypes": {
+				"ItemValue1": {}
+			}
+		},
+		{
+			"type": "OtherValue",
+			"interface": "IsOtherValue",
+			"package": "pkg",
+			"subtypes": {
+				"OtherValue1": {}
+			}
+		}
+	]
+}`)
+
+	cmd := exec.Command("go", "run", ".", "-config", configFile, "-proto")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generator failed: %v\nOutput: %s", err, output)
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	want := ".polygen.json item.go item_value_polygen.proto item_value_polygen_proto.go item_value_polygen_proto.lock.json"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("proto mode must write only the protobuf files\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 func createFile(t *testing.T, path, content string) {
 	t.Helper()
 
@@ -0,0 +1,106 @@
+// Package protopb stands in for the package protoc-gen-go generates from shape_message_polygen.proto.
+//
+// It declares only the parts the generated conversions use: the message structs, the getter of the
+// oneof and its wrapper types. Keeping it hand-written lets the tests run without protoc.
+package protopb
+
+type ShapeMessage struct {
+	// Types that are valid to be assigned to Subtype:
+	//
+	//	*ShapeMessage_Circle
+	//	*ShapeMessage_Empty
+	//	*ShapeMessage_Label
+	//	*ShapeMessage_Line
+	//	*ShapeMessage_Polygon
+	//	*ShapeMessage_Rectangle
+	Subtype isShapeMessage_Subtype
+}
+
+func (x *ShapeMessage) GetSubtype() isShapeMessage_Subtype {
+	if x != nil {
+		return x.Subtype
+	}
+
+	return nil
+}
+
+type isShapeMessage_Subtype interface {
+	isShapeMessage_Subtype()
+}
+
+type ShapeMessage_Circle struct {
+	Circle *Circle
+}
+
+type ShapeMessage_Empty struct {
+	Empty *Empty
+}
+
+type ShapeMessage_Label struct {
+	Label *Label
+}
+
+type ShapeMessage_Line struct {
+	Line *Line
+}
+
+type ShapeMessage_Polygon struct {
+	Polygon *Polygon
+}
+
+type ShapeMessage_Rectangle struct {
+	Rectangle *Rectangle
+}
+
+func (*ShapeMessage_Circle) isShapeMessage_Subtype() {}
+
+func (*ShapeMessage_Empty) isShapeMessage_Subtype() {}
+
+func (*ShapeMessage_Label) isShapeMessage_Subtype() {}
+
+func (*ShapeMessage_Line) isShapeMessage_Subtype() {}
+
+func (*ShapeMessage_Polygon) isShapeMessage_Subtype() {}
+
+func (*ShapeMessage_Rectangle) isShapeMessage_Subtype() {}
+
+type Circle struct {
+	Radius float64
+}
+
+type Empty struct{}
+
+type Label struct {
+	Text string
+	Meta *LabelMeta
+}
+
+type LabelMeta struct {
+	Author string
+}
+
+type Line struct {
+	Kind   string
+	Length float64
+}
+
+type Polygon struct {
+	Points []*PolygonPoints
+	Labels []string
+}
+
+type PolygonPoints struct {
+	X float64
+	Y float64
+}
+
+type Rectangle struct {
+	Width  float64
+	Height float64
+	Style  *RectangleStyle
+}
+
+type RectangleStyle struct {
+	Color string
+	Fill  bool
+}
@@ -1,5 +1,11 @@
 package tests
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
 //go:generate go run ..
 
 type IsShape interface {
@@ -23,6 +29,14 @@ type Rectangle struct {
 
 func (Rectangle) isShape() {}
 
+func (r Rectangle) Validate() error {
+	if r.Width < 0 || r.Height < 0 {
+		return fmt.Errorf("negative size %vx%v", r.Width, r.Height)
+	}
+
+	return nil
+}
+
 type Polygon struct {
 	Points []struct {
 		X float64
@@ -33,6 +47,16 @@ type Polygon struct {
 
 func (*Polygon) isShape() {}
 
+func (p *Polygon) Validate() error {
+	for _, label := range p.Labels {
+		if label == "" {
+			return errors.New("empty label")
+		}
+	}
+
+	return nil
+}
+
 type Group struct {
 	Name       string
 	Attributes map[string]any
@@ -43,3 +67,86 @@ func (*Group) isShape() {}
 type Empty struct{}
 
 func (Empty) isShape() {}
+
+// Label has its own meta object, which a nested discriminator is merged into.
+type Label struct {
+	Text string
+	Meta struct {
+		Author string `json:"author,omitempty"`
+	} `json:"meta"`
+}
+
+func (Label) isShape() {}
+
+// Arc and Line carry their discriminator in a field of their own.
+type Arc struct {
+	Kind  string `json:"kind"`
+	Angle float64
+}
+
+func (Arc) isShape() {}
+
+type Line struct {
+	Kind   string `json:"kind"`
+	Length float64
+}
+
+func (*Line) isShape() {}
+
+// ShapeKind is a discriminator type encoded as text.
+type ShapeKind int
+
+const (
+	ShapeKindCircle ShapeKind = iota + 1
+	ShapeKindRectangle
+)
+
+var shapeKindNames = map[ShapeKind]string{
+	ShapeKindCircle:    "CIRCLE",
+	ShapeKindRectangle: "RECTANGLE",
+}
+
+func (k ShapeKind) MarshalText() ([]byte, error) {
+	name, ok := shapeKindNames[k]
+	if !ok {
+		return nil, fmt.Errorf("unknown shape kind %d", int(k))
+	}
+
+	return []byte(name), nil
+}
+
+func (k *ShapeKind) UnmarshalText(text []byte) error {
+	for kind, name := range shapeKindNames {
+		if name == string(text) {
+			*k = kind
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown shape kind %q", text)
+}
+
+// upgradeCircle upgrades circles stored before version 2, which had their radius in "r".
+func upgradeCircle(data []byte, version int) ([]byte, error) {
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	// Upgrade functions are given the members of the subtype only
+	if _, ok := object["type"]; ok {
+		return nil, errors.New("unexpected discriminator")
+	}
+
+	if _, ok := object["v"]; ok {
+		return nil, errors.New("unexpected version")
+	}
+
+	if version < 2 {
+		object["Radius"] = object["r"]
+		delete(object, "r")
+	}
+
+	return json.Marshal(object)
+}
@@ -0,0 +1,160 @@
+// Code generated by polygen; DO NOT EDIT.
+
+package tests
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// MarshalXML encodes the subtype as the start element with the type attribute holding its type name.
+func (v ShapeAttributed) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if v.IsShape == nil {
+		return nil
+	}
+
+	typeName, _, err := _ShapeAttributedGetType(v.IsShape)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot get subtype to marshal for ShapeAttributed: %v", err)
+	}
+
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: typeName})
+
+	if err := e.EncodeElement(v.IsShape, start); err != nil {
+		return fmt.Errorf("polygen: cannot marshal IsShape for ShapeAttributed: %v", err)
+	}
+
+	return nil
+}
+
+// UnmarshalXML decodes the subtype named by the type attribute of start.
+func (v *ShapeAttributed) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeAttributedGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeAttributed: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	typeName := currTypeName
+
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "type" {
+			typeName = attr.Value
+
+			break
+		}
+	}
+
+	if typeName == "" {
+		return errors.New("polygen: missing discriminator attribute type for ShapeAttributed")
+	}
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeAttributed: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeAttributed: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := d.DecodeElement(&vv, &start); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeAttributed: %v", err)
+			}
+
+			value = vv
+		}
+	case "empty":
+		if currTypeName == "empty" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Empty)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeAttributed: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Empty)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeAttributed: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Empty
+			if err := d.DecodeElement(&vv, &start); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeAttributed: %v", err)
+			}
+
+			value = vv
+		}
+	case "polygon":
+		var vv *Polygon
+		if currTypeName == "polygon" {
+			vv = v.IsShape.(*Polygon)
+		}
+		if err := d.DecodeElement(&vv, &start); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Polygon for ShapeAttributed: %v", err)
+		}
+
+		value = vv
+	case "rectangle":
+		if currTypeName == "rectangle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Rectangle)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeAttributed: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Rectangle)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeAttributed: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Rectangle
+			if err := d.DecodeElement(&vv, &start); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeAttributed: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeAttributed: %v", typeName)
+	}
+
+	*v = ShapeAttributed{
+		IsShape: value,
+	}
+
+	return nil
+}
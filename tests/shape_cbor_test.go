@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ykalchevskiy/polygen/cbor"
+)
+
+// jsonToCBOR converts the JSON test input to CBOR, keeping input that is not JSON as is.
+func jsonToCBOR(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var value any
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return []byte(data)
+	}
+
+	encoded, err := cbor.Marshal(value)
+	if err != nil {
+		t.Fatalf("cbor.Marshal() error = %v", err)
+	}
+
+	return encoded
+}
+
+func TestShapeMarshalCBOR(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		shape Shape
+		want  string
+	}{
+		{
+			name:  "circle",
+			shape: Shape{IsShape: Circle{Radius: 5}},
+			// {"type": "circle", "Radius": 5.0}, the discriminator first
+			want: "a2 6474797065 66636972636c65 66526164697573 fb4014000000000000",
+		},
+		{
+			name:  "empty type",
+			shape: Shape{IsShape: Empty{}},
+			want:  "a1 6474797065 65656d707479",
+		},
+		{
+			name:  "nil value",
+			shape: Shape{},
+			want:  "f6",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cbor.Marshal(tt.shape)
+			if err != nil {
+				t.Fatalf("MarshalCBOR() error = %v", err)
+			}
+
+			want, _ := hex.DecodeString(strings.ReplaceAll(tt.want, " ", ""))
+			if !bytes.Equal(got, want) {
+				t.Errorf("MarshalCBOR() = %x, want %x", got, want)
+			}
+		})
+	}
+}
+
+func TestShapeCBORRoundTrip(t *testing.T) {
+	for _, isStrict := range []bool{false, true} {
+		for _, tt := range marshalTests {
+			t.Run(tt.name, func(t *testing.T) {
+				var (
+					data []byte
+					err  error
+					got  any
+				)
+
+				if isStrict {
+					data, err = cbor.Marshal(ShapeStrict{IsShape: tt.shape.IsShape})
+				} else {
+					data, err = cbor.Marshal(tt.shape)
+				}
+
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("MarshalCBOR() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+
+				if isStrict {
+					var decoded ShapeStrict
+					err = cbor.Unmarshal(data, &decoded)
+					got = decoded
+				} else {
+					var decoded Shape
+					err = cbor.Unmarshal(data, &decoded)
+					got = decoded
+				}
+
+				if err != nil {
+					t.Fatalf("UnmarshalCBOR() error = %v", err)
+				}
+
+				// Integers in the any values of Group come back as int64, so compare the JSON encodings
+				gotJSON, err := json.Marshal(got)
+				if err != nil {
+					t.Fatalf("MarshalJSON() error = %v", err)
+				}
+
+				var gotObj, wantObj any
+				if err := json.Unmarshal(gotJSON, &gotObj); err != nil {
+					t.Fatalf("Failed to unmarshal result: %v", err)
+				}
+				if err := json.Unmarshal([]byte(tt.want), &wantObj); err != nil {
+					t.Fatalf("Failed to unmarshal expected: %v", err)
+				}
+				if !reflect.DeepEqual(gotObj, wantObj) {
+					t.Errorf("CBOR round trip = %s, want %s", gotJSON, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestShapeUnmarshalCBOR(t *testing.T) {
+	for _, isStrict := range []bool{false, true} {
+		for _, tt := range append(unmarshalTests, extraUnmarshalTests...) {
+			if (tt.strictOnly && !isStrict) || (tt.nonStrictOnly && isStrict) {
+				continue
+			}
+
+			t.Run(tt.name, func(t *testing.T) {
+				data := jsonToCBOR(t, tt.json)
+
+				var (
+					got Shape
+					err error
+				)
+
+				if isStrict {
+					var strict ShapeStrict
+					err = cbor.Unmarshal(data, &strict)
+					got = Shape(strict)
+				} else {
+					err = cbor.Unmarshal(data, &got)
+				}
+
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("UnmarshalCBOR() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err == nil && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("UnmarshalCBOR() = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestShapeUnmarshalCBOR_missingDiscriminator(t *testing.T) {
+	data := jsonToCBOR(t, `{"Radius":5}`)
+
+	var got Shape
+	if err := cbor.Unmarshal(data, &got); err == nil || err.Error() != "polygen: missing discriminator type for Shape" {
+		t.Errorf("UnmarshalCBOR() error = %v, want missing discriminator", err)
+	}
+
+	var gotDefault ShapeDefault
+	if err := cbor.Unmarshal(data, &gotDefault); err != nil {
+		t.Fatalf("UnmarshalCBOR() error = %v", err)
+	}
+	if want := (ShapeDefault{IsShape: Circle{Radius: 5}}); !reflect.DeepEqual(gotDefault, want) {
+		t.Errorf("UnmarshalCBOR() = %+v, want %+v", gotDefault, want)
+	}
+}
+
+func TestShapeUnmarshalCBOR_update(t *testing.T) {
+	got := Shape{IsShape: Rectangle{Width: 1, Height: 2}}
+	if err := cbor.Unmarshal(jsonToCBOR(t, `{"Height":3}`), &got); err != nil {
+		t.Fatalf("UnmarshalCBOR() error = %v", err)
+	}
+	if want := (Shape{IsShape: Rectangle{Width: 1, Height: 3}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalCBOR() = %+v, want %+v", got, want)
+	}
+}
+
+func TestShapeNestedCBOR(t *testing.T) {
+	data, err := cbor.Marshal(ShapeNested{IsShape: Label{Text: "hi"}})
+	if err != nil {
+		t.Fatalf("MarshalCBOR() error = %v", err)
+	}
+
+	var got any
+	if err := cbor.Unmarshal(data, &got); err != nil {
+		t.Fatalf("cbor.Unmarshal() error = %v", err)
+	}
+	if want := map[string]any{"Text": "hi", "meta": map[string]any{"type": "label"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MarshalCBOR() = %v, want %v", got, want)
+	}
+
+	var decoded ShapeNested
+	if err := cbor.Unmarshal(jsonToCBOR(t, `{"meta":{"type":"circle"},"Radius":5}`), &decoded); err != nil {
+		t.Fatalf("UnmarshalCBOR() error = %v", err)
+	}
+	if want := (ShapeNested{IsShape: Circle{Radius: 5}}); !reflect.DeepEqual(decoded, want) {
+		t.Errorf("UnmarshalCBOR() = %+v, want %+v", decoded, want)
+	}
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Equal compares rectangles by size only, checking that Shape.Equal uses the Equal method of its subtype.
+func (r Rectangle) Equal(other Rectangle) bool {
+	return r.Width == other.Width && r.Height == other.Height
+}
+
+// Clone copies only the name of a group, checking that Shape.Clone uses the Clone method of its subtype.
+func (g *Group) Clone() *Group {
+	return &Group{Name: g.Name}
+}
+
+func TestShapeEqual(t *testing.T) {
+	styled := Rectangle{Width: 1, Height: 2}
+	styled.Style.Color = "red"
+
+	for _, tt := range []struct {
+		name string
+		a, b Shape
+		want bool
+	}{
+		{name: "nil", a: Shape{}, b: Shape{}, want: true},
+		{name: "nil and circle", a: Shape{}, b: Shape{IsShape: Circle{}}, want: false},
+		{name: "circle and nil", a: Shape{IsShape: Circle{}}, b: Shape{}, want: false},
+		{name: "same circle", a: Shape{IsShape: Circle{Radius: 1}}, b: Shape{IsShape: Circle{Radius: 1}}, want: true},
+		{name: "different circle", a: Shape{IsShape: Circle{Radius: 1}}, b: Shape{IsShape: Circle{Radius: 2}}, want: false},
+		{name: "value and pointer", a: Shape{IsShape: Circle{Radius: 1}}, b: Shape{IsShape: &Circle{Radius: 1}}, want: true},
+		{name: "pointer and value", a: Shape{IsShape: &Circle{Radius: 1}}, b: Shape{IsShape: Circle{Radius: 1}}, want: true},
+		{name: "nil pointer and value", a: Shape{IsShape: (*Circle)(nil)}, b: Shape{IsShape: Circle{}}, want: false},
+		{name: "nil pointers", a: Shape{IsShape: (*Polygon)(nil)}, b: Shape{IsShape: (*Polygon)(nil)}, want: true},
+		{name: "different subtypes", a: Shape{IsShape: Circle{}}, b: Shape{IsShape: Empty{}}, want: false},
+		{
+			name: "deep pointer subtype",
+			a:    Shape{IsShape: &Polygon{Labels: []string{"a"}}},
+			b:    Shape{IsShape: &Polygon{Labels: []string{"a"}}},
+			want: true,
+		},
+		{
+			name: "deep pointer subtype differs",
+			a:    Shape{IsShape: &Polygon{Labels: []string{"a"}}},
+			b:    Shape{IsShape: &Polygon{Labels: []string{"b"}}},
+			want: false,
+		},
+		{name: "subtype Equal method", a: Shape{IsShape: styled}, b: Shape{IsShape: &Rectangle{Width: 1, Height: 2}}, want: true},
+		{name: "unknown subtype", a: Shape{IsShape: Arc{Angle: 1}}, b: Shape{IsShape: Arc{Angle: 1}}, want: true},
+		{name: "unknown and known subtype", a: Shape{IsShape: Arc{}}, b: Shape{IsShape: Circle{}}, want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShapeClone(t *testing.T) {
+	polygon := &Polygon{
+		Points: []struct {
+			X float64
+			Y float64
+		}{{X: 1, Y: 2}},
+		Labels: []string{"a"},
+	}
+
+	for _, tt := range []struct {
+		name  string
+		shape Shape
+	}{
+		{name: "nil", shape: Shape{}},
+		{name: "value", shape: Shape{IsShape: Circle{Radius: 1}}},
+		{name: "value as pointer", shape: Shape{IsShape: &Circle{Radius: 1}}},
+		{name: "nil pointer", shape: Shape{IsShape: (*Polygon)(nil)}},
+		{name: "pointer subtype", shape: Shape{IsShape: polygon}},
+		{name: "unknown subtype", shape: Shape{IsShape: &Arc{Angle: 1}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.shape.Clone(); !reflect.DeepEqual(got, tt.shape) {
+				t.Errorf("Clone() = %+v, want %+v", got, tt.shape)
+			}
+		})
+	}
+
+	t.Run("deep", func(t *testing.T) {
+		clone := Shape{IsShape: polygon}.Clone()
+
+		got := clone.IsShape.(*Polygon)
+		if got == polygon {
+			t.Fatal("Clone() returned the same pointer")
+		}
+
+		got.Points[0].X = 10
+		got.Labels[0] = "b"
+
+		if polygon.Points[0].X != 1 || polygon.Labels[0] != "a" {
+			t.Errorf("Clone() shares data with the original: %+v", polygon)
+		}
+	})
+
+	t.Run("subtype Clone method", func(t *testing.T) {
+		group := &Group{Name: "g", Attributes: map[string]any{"a": 1}}
+
+		if got, want := (Shape{IsShape: group}).Clone(), (Shape{IsShape: &Group{Name: "g"}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("Clone() = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestShapeDeepCopy(t *testing.T) {
+	value := map[string]any{
+		"list":   []any{1, "a"},
+		"nested": map[string]any{"b": true},
+		"array":  [2][]int{{1}, {2}},
+		"nil":    nil,
+	}
+
+	got := _ShapeDeepCopy(reflect.ValueOf(value)).Interface().(map[string]any)
+	if !reflect.DeepEqual(got, value) {
+		t.Fatalf("_ShapeDeepCopy() = %v, want %v", got, value)
+	}
+
+	got["list"].([]any)[0] = 2
+	got["nested"].(map[string]any)["b"] = false
+	got["array"].([2][]int)[0][0] = 3
+
+	if value["list"].([]any)[0] != 1 || value["nested"].(map[string]any)["b"] != true || value["array"].([2][]int)[0][0] != 1 {
+		t.Errorf("_ShapeDeepCopy() shares data with the original: %v", value)
+	}
+}
@@ -0,0 +1,189 @@
+// Code generated by polygen; DO NOT EDIT.
+
+//go:build go1.25 && goexperiment.jsonv2
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+)
+
+func (v ShapeCode) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if v.IsShape == nil {
+		return enc.WriteValue([]byte("null"))
+	}
+
+	// Marshal the implementation first to get its fields
+	implData, err := json.Marshal(v.IsShape, enc.Options())
+	if err != nil {
+		return fmt.Errorf("polygen: cannot marshal IsShape for ShapeCode: %v", err)
+	}
+
+	if bytes.Equal(implData, []byte("null")) {
+		return enc.WriteValue(implData)
+	}
+
+	typeName, _, err := _ShapeCodeGetType(v.IsShape)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot get subtype to marshal for ShapeCode: %v", err)
+	}
+
+	typeNameData, err := json.Marshal(typeName, enc.Options())
+	if err != nil {
+		return fmt.Errorf("polygen: cannot marshal discriminator type for ShapeCode: %v", err)
+	}
+
+	// If it's an empty object, just return discriminator
+	if bytes.Equal(implData, []byte("{}")) {
+		return enc.WriteValue([]byte(`{"type":` + string(typeNameData) + `}`))
+	}
+
+	if len(implData) == 0 || implData[0] != '{' {
+		return fmt.Errorf("polygen: expected JSON object for IsShape (%T), got %s", v.IsShape, implData)
+	}
+
+	// Otherwise, combine discriminator with implementation fields
+	var buf bytes.Buffer
+
+	buf.Grow(len(`{"type":,`) + len(typeNameData) + len(implData) - 1)
+	buf.WriteString(`{"type":`)
+	buf.Write(typeNameData)
+	buf.WriteString(`,`)
+	buf.Write(implData[1:])
+
+	return enc.WriteValue(buf.Bytes())
+}
+
+func (v *ShapeCode) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	var (
+		currTypeName      int
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeCodeGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeCode: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	fullData := &struct {
+		TypeName int            `json:"type"`
+		Data     jsontext.Value `json:",unknown"`
+	}{
+		TypeName: currTypeName,
+		Data:     []byte("{}"),
+	}
+
+	if err := json.UnmarshalDecode(dec, &fullData, json.RejectUnknownMembers(false)); err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal: %v", err)
+	}
+
+	if fullData == nil {
+		*v = ShapeCode{}
+
+		return nil
+	}
+
+	if fullData.TypeName == 0 {
+		return errors.New("polygen: missing discriminator type for ShapeCode")
+	}
+
+	typeName := fullData.TypeName
+
+	var value IsShape
+
+	switch typeName {
+	case 1:
+		if currTypeName == 1 {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeCode: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeCode: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeCode: %v", err)
+			}
+
+			value = vv
+		}
+	case 3:
+		if currTypeName == 3 {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Empty)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeCode: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Empty)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeCode: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Empty
+			if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeCode: %v", err)
+			}
+
+			value = vv
+		}
+	case 2:
+		if currTypeName == 2 {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Rectangle)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeCode: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Rectangle)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeCode: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Rectangle
+			if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeCode: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeCode: %v", typeName)
+	}
+
+	*v = ShapeCode{
+		IsShape: value,
+	}
+
+	return nil
+}
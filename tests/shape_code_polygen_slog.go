@@ -0,0 +1,59 @@
+// Code generated by polygen; DO NOT EDIT.
+
+//go:build go1.21
+
+package tests
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+var (
+	_ slog.LogValuer = ShapeCode{}
+	_ fmt.Stringer   = ShapeCode{}
+)
+
+// LogValue groups the type name of the subtype under the type key with the subtype under "value",
+// which slog resolves with its own LogValue if it implements slog.LogValuer.
+func (v ShapeCode) LogValue() slog.Value {
+	if v.IsShape == nil {
+		return slog.AnyValue(nil)
+	}
+
+	typeName, _, err := _ShapeCodeGetType(v.IsShape)
+	if err != nil {
+		// An unknown subtype is logged as is
+		return slog.AnyValue(v.IsShape)
+	}
+
+	return slog.GroupValue(
+		slog.Any("type", typeName),
+		slog.Any("value", v.IsShape),
+	)
+}
+
+// String returns the type name of the subtype followed by its fields, such as 1{...}.
+func (v ShapeCode) String() string {
+	if v.IsShape == nil {
+		return "<nil>"
+	}
+
+	typeName, _, err := _ShapeCodeGetType(v.IsShape)
+	if err != nil {
+		// An unknown subtype is printed as is
+		return fmt.Sprintf("%+v", v.IsShape)
+	}
+
+	value := reflect.ValueOf(v.IsShape)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return fmt.Sprintf("%v<nil>", typeName)
+		}
+
+		value = value.Elem()
+	}
+
+	return fmt.Sprintf("%v%+v", typeName, value.Interface())
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestShapeCode(t *testing.T) {
+	t.Run("marshal", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			shape ShapeCode
+			want  string
+		}{
+			{
+				name:  "circle",
+				shape: ShapeCode{IsShape: Circle{Radius: 5}},
+				want:  `{"type":1,"Radius":5}`,
+			},
+			{
+				name:  "empty",
+				shape: ShapeCode{IsShape: Empty{}},
+				want:  `{"type":3}`,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := json.Marshal(tt.shape)
+				if err != nil {
+					t.Fatalf("MarshalJSON() error = %v", err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			json    string
+			want    ShapeCode
+			wantErr bool
+		}{
+			{
+				name: "circle",
+				json: `{"type":1,"Radius":5}`,
+				want: ShapeCode{IsShape: Circle{Radius: 5}},
+			},
+			{
+				name: "rectangle",
+				json: `{"type":2,"Width":1,"Height":2}`,
+				want: ShapeCode{IsShape: Rectangle{Width: 1, Height: 2}},
+			},
+			{
+				name:    "unknown code",
+				json:    `{"type":4}`,
+				wantErr: true,
+			},
+			{
+				name:    "string code",
+				json:    `{"type":"1"}`,
+				wantErr: true,
+			},
+			{
+				name:    "missing discriminator",
+				json:    `{"Radius":5}`,
+				wantErr: true,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var got ShapeCode
+				err := json.Unmarshal([]byte(tt.json), &got)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err == nil && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		got := ShapeCode{IsShape: Circle{Radius: 5}}
+		if err := json.Unmarshal([]byte(`{"Radius":10}`), &got); err != nil {
+			t.Fatalf("UnmarshalJSON() error = %v", err)
+		}
+		if want := (ShapeCode{IsShape: Circle{Radius: 10}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalJSON() = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestShapeKinded(t *testing.T) {
+	shape := ShapeKinded{IsShape: Rectangle{Width: 1, Height: 2}}
+
+	data, err := json.Marshal(shape)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	want := `{"kind":"RECTANGLE","Width":1,"Height":2,"Style":{"Color":"","Fill":false}}`
+	if string(data) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", data, want)
+	}
+
+	var got ShapeKinded
+	if err := json.Unmarshal([]byte(`{"kind":"CIRCLE","Radius":3}`), &got); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if want := (ShapeKinded{IsShape: Circle{Radius: 3}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalJSON() = %+v, want %+v", got, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"kind":"TRIANGLE"}`), &got); err == nil {
+		t.Error("UnmarshalJSON() expected error for an unknown kind")
+	}
+}
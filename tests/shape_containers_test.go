@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShapeListUnmarshalJSON(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		json    string
+		want    ShapeList
+		wantErr string
+	}{
+		{
+			name: "elements",
+			json: `[{"type":"circle","Radius":1}, {"type":"polygon","Labels":["a"]}, null, {"type":"empty"}]`,
+			want: ShapeList{
+				{IsShape: Circle{Radius: 1}},
+				{IsShape: &Polygon{Labels: []string{"a"}}},
+				{},
+				{IsShape: Empty{}},
+			},
+		},
+		{name: "empty", json: `[]`, want: ShapeList{}},
+		{name: "null", json: `null`, want: nil},
+		{name: "object", json: `{}`, wantErr: "polygen: cannot unmarshal non-array into ShapeList"},
+		{
+			name:    "invalid element",
+			json:    `[{"type":"circle"}, {"type":"unknown"}]`,
+			wantErr: "polygen: cannot unmarshal element 1 of ShapeList: polygen: unknown subtype for Shape: unknown",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ShapeList
+
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("UnmarshalJSON() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("UnmarshalJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShapeMapUnmarshalJSON(t *testing.T) {
+	var got ShapeMap
+	if err := json.Unmarshal([]byte(`{"a":{"type":"circle","Radius":1},"b":{"type":"empty"},"c":null}`), &got); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+
+	want := ShapeMap{"a": {IsShape: Circle{Radius: 1}}, "b": {IsShape: Empty{}}, "c": {}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalJSON() = %+v, want %+v", got, want)
+	}
+
+	err := json.Unmarshal([]byte(`{"a":{"type":"unknown"}}`), &got)
+	if want := `polygen: cannot unmarshal key "a" of ShapeMap`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("UnmarshalJSON() error = %v, want %q", err, want)
+	}
+
+	if err := json.Unmarshal([]byte(`[]`), &got); err == nil || !strings.Contains(err.Error(), "non-object") {
+		t.Errorf("UnmarshalJSON() error = %v, want non-object", err)
+	}
+
+	if err := json.Unmarshal([]byte(`null`), &got); err != nil || got != nil {
+		t.Errorf("UnmarshalJSON() = %v, %v, want nil map", got, err)
+	}
+}
+
+func TestShapeContainersRoundTrip(t *testing.T) {
+	list := ShapeList{{IsShape: Circle{Radius: 1}}, {IsShape: &Polygon{Labels: []string{"a"}}}}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var got ShapeList
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, list) {
+		t.Errorf("round trip = %+v, want %+v", got, list)
+	}
+}
+
+func TestShapeListFilter(t *testing.T) {
+	polygon := &Polygon{Labels: []string{"a"}}
+
+	list := ShapeList{
+		{IsShape: Circle{Radius: 1}},
+		{IsShape: polygon},
+		{IsShape: &Circle{Radius: 2}},
+		{IsShape: (*Circle)(nil)},
+		{IsShape: (*Polygon)(nil)},
+		{},
+		{IsShape: Arc{}},
+	}
+
+	if got, want := list.FilterCircle(), []Circle{{Radius: 1}, {Radius: 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterCircle() = %+v, want %+v", got, want)
+	}
+
+	if got, want := list.FilterPolygon(), []*Polygon{polygon, nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterPolygon() = %+v, want %+v", got, want)
+	}
+
+	if got := list.FilterRectangle(); got != nil {
+		t.Errorf("FilterRectangle() = %+v, want nil", got)
+	}
+
+	if got, want := list.CountByCircle(), 2; got != want {
+		t.Errorf("CountByCircle() = %d, want %d", got, want)
+	}
+
+	if got, want := list.CountByPolygon(), 2; got != want {
+		t.Errorf("CountByPolygon() = %d, want %d", got, want)
+	}
+
+	if got := list.CountByRectangle(); got != 0 {
+		t.Errorf("CountByRectangle() = %d, want 0", got)
+	}
+}
+
+func TestShapeMapFilter(t *testing.T) {
+	m := ShapeMap{
+		"a": {IsShape: Circle{Radius: 1}},
+		"b": {IsShape: &Circle{Radius: 2}},
+		"c": {IsShape: Empty{}},
+		"d": {},
+	}
+
+	if got, want := m.FilterCircle(), map[string]Circle{"a": {Radius: 1}, "b": {Radius: 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterCircle() = %+v, want %+v", got, want)
+	}
+
+	if got, want := m.CountByCircle(), 2; got != want {
+		t.Errorf("CountByCircle() = %d, want %d", got, want)
+	}
+
+	if got, want := m.CountByEmpty(), 1; got != want {
+		t.Errorf("CountByEmpty() = %d, want %d", got, want)
+	}
+}
+
+func TestShapeCodeListCount(t *testing.T) {
+	var list ShapeCodeList
+	if err := json.Unmarshal([]byte(`[{"type":1,"Radius":1},{"type":2},{"type":1}]`), &list); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+
+	if got, want := list.CountByCircle(), 2; got != want {
+		t.Errorf("CountByCircle() = %d, want %d", got, want)
+	}
+
+	if got, want := list.CountByRectangle(), 1; got != want {
+		t.Errorf("CountByRectangle() = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build go1.23
+
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShapeArrayDecoderAll(t *testing.T) {
+	dec := NewShapeArrayDecoder(strings.NewReader(`[{"type":"circle","Radius":1}, {"type":"empty"}, {"type":"unknown"}]`))
+
+	var (
+		got  []Shape
+		errs []error
+	)
+
+	for shape, err := range dec.All() {
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		got = append(got, shape)
+	}
+
+	if want := []Shape{{IsShape: Circle{Radius: 1}}, {IsShape: Empty{}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %+v, want %+v", got, want)
+	}
+
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "element 2 of Shape array") {
+		t.Errorf("All() errors = %v, want the error of element 2", errs)
+	}
+}
+
+func TestShapeArrayDecoderAll_break(t *testing.T) {
+	dec := NewShapeArrayDecoder(strings.NewReader(`[{"type":"circle"}, {"type":"empty"}]`))
+
+	for range dec.All() {
+		break
+	}
+
+	// The iterator stops early without consuming the rest of the array
+	if !dec.Next() || !reflect.DeepEqual(dec.Value(), Shape{IsShape: Empty{}}) {
+		t.Errorf("Next() = %+v, %v, want empty", dec.Value(), dec.Err())
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShapeArrayDecoder(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		json    string
+		want    []Shape
+		wantErr string
+	}{
+		{
+			name: "elements",
+			json: ` [{"type":"circle","Radius":1}, null, {"type":"polygon","Labels":["a"]}] `,
+			want: []Shape{{IsShape: Circle{Radius: 1}}, {}, {IsShape: &Polygon{Labels: []string{"a"}}}},
+		},
+		{name: "empty", json: `[]`},
+		{name: "null", json: `null`},
+		{
+			name:    "invalid element",
+			json:    `[{"type":"circle"}, {"type":"unknown"}, {"type":"empty"}]`,
+			want:    []Shape{{IsShape: Circle{}}},
+			wantErr: "polygen: cannot decode element 1 of Shape array at offset 18: polygen: unknown subtype for Shape: unknown",
+		},
+		{
+			name:    "truncated",
+			json:    `[{"type":"empty"}, {"type":`,
+			want:    []Shape{{IsShape: Empty{}}},
+			wantErr: "polygen: cannot decode element 1 of Shape array at offset 17: unexpected EOF",
+		},
+		{name: "not an array", json: `{}`, wantErr: "polygen: cannot decode Shape array at offset 1: not an array"},
+		{name: "empty input", json: ``, wantErr: "polygen: cannot decode Shape array at offset 0: EOF"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewShapeArrayDecoder(strings.NewReader(tt.json))
+
+			var got []Shape
+			for dec.Next() {
+				if dec.Index() != len(got) {
+					t.Errorf("Index() = %d, want %d", dec.Index(), len(got))
+				}
+
+				got = append(got, dec.Value())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Value() = %+v, want %+v", got, tt.want)
+			}
+
+			if err := dec.Err(); (tt.wantErr == "" && err != nil) || (tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr)) {
+				t.Errorf("Err() = %v, want %q", err, tt.wantErr)
+			}
+
+			if dec.Next() {
+				t.Error("Next() = true after the end")
+			}
+		})
+	}
+}
+
+func TestShapeArrayDecoder_leavesInputAfterArray(t *testing.T) {
+	r := strings.NewReader(`[{"type":"empty"}] [{"type":"circle"}]`)
+
+	first := NewShapeArrayDecoder(io.LimitReader(r, 19))
+	for first.Next() {
+	}
+
+	if err := first.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	second := NewShapeArrayDecoder(r)
+	if !second.Next() || !reflect.DeepEqual(second.Value(), Shape{IsShape: Circle{}}) {
+		t.Errorf("Next() = %+v, %v, want circle", second.Value(), second.Err())
+	}
+}
+
+func TestShapeStrictArrayDecoder(t *testing.T) {
+	dec := NewShapeStrictArrayDecoder(strings.NewReader(`[{"type":"circle","Radius":1,"Extra":true}]`))
+	if dec.Next() {
+		t.Fatalf("Next() = true, want the unknown field rejected")
+	}
+
+	if err := dec.Err(); err == nil || !strings.Contains(err.Error(), "element 0 of ShapeStrict array") {
+		t.Errorf("Err() = %v, want the element error", err)
+	}
+}
+
+func TestShapeArrayDecoder_truncated(t *testing.T) {
+	dec := NewShapeArrayDecoder(strings.NewReader(`[{"type":"empty"}, {"type":`))
+	for dec.Next() {
+	}
+
+	if err := dec.Err(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Err() = %v, want io.ErrUnexpectedEOF wrapped", err)
+	}
+
+	// Where the missing end of the array is reported differs between encoding/json and jsonv2
+	dec = NewShapeArrayDecoder(strings.NewReader(`[{"type":"empty"}`))
+	if !dec.Next() || dec.Next() {
+		t.Fatalf("Next() did not stop after the only element")
+	}
+
+	if err := dec.Err(); err == nil || !strings.Contains(err.Error(), "of Shape array at offset 17") {
+		t.Errorf("Err() = %v, want an error at offset 17", err)
+	}
+}
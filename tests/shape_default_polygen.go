@@ -208,7 +208,7 @@ func (v *ShapeDefault) UnmarshalJSON(data []byte) error {
 			value = vv
 		}
 	default:
-		return fmt.Errorf("polygen: unknown subtype for ShapeDefault: %s", typeName)
+		return fmt.Errorf("polygen: unknown subtype for ShapeDefault: %v", typeName)
 	}
 
 	*v = ShapeDefault{
@@ -0,0 +1,199 @@
+// Code generated by polygen; DO NOT EDIT.
+
+package tests
+
+import (
+	"fmt"
+
+	"github.com/ykalchevskiy/polygen/cbor"
+)
+
+var (
+	_ cbor.Marshaler   = ShapeDefault{}
+	_ cbor.Unmarshaler = (*ShapeDefault)(nil)
+)
+
+// MarshalCBOR encodes the subtype as a CBOR map with the type key holding its type name first.
+func (v ShapeDefault) MarshalCBOR() ([]byte, error) {
+	if v.IsShape == nil {
+		return cbor.Marshal(nil)
+	}
+
+	typeName, _, err := _ShapeDefaultGetType(v.IsShape)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot get subtype to marshal for ShapeDefault: %v", err)
+	}
+
+	impl := v.IsShape
+
+	implData, err := cbor.Marshal(impl)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape for ShapeDefault: %v", err)
+	}
+
+	if cbor.IsNull(implData) {
+		return implData, nil
+	}
+
+	typeNameData, err := cbor.Marshal(typeName)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal discriminator type for ShapeDefault: %v", err)
+	}
+
+	data, err := cbor.InsertPath(implData, []string{"type"}, typeNameData)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape (%T) for ShapeDefault: %v", v.IsShape, err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalCBOR decodes the subtype named by the type key of the CBOR map.
+func (v *ShapeDefault) UnmarshalCBOR(data []byte) error {
+	if cbor.IsNull(data) {
+		*v = ShapeDefault{}
+
+		return nil
+	}
+
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeDefaultGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeDefault: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	// First extract the type key, so that it does not get in the way of decoding the subtype
+	typeNameData, data, err := cbor.ExtractPath(data, []string{"type"})
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal discriminator type for ShapeDefault: %v", err)
+	}
+
+	typeName := currTypeName
+	if typeNameData != nil {
+		if err := cbor.Unmarshal(typeNameData, &typeName); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal discriminator type for ShapeDefault: %v", err)
+		}
+	}
+
+	if typeName == "" {
+		typeName = "circle"
+	}
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeDefault: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeDefault: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := cbor.Unmarshal(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeDefault: %v", err)
+			}
+
+			value = vv
+		}
+	case "empty":
+		if currTypeName == "empty" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Empty)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeDefault: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Empty)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeDefault: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Empty
+			if err := cbor.Unmarshal(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeDefault: %v", err)
+			}
+
+			value = vv
+		}
+	case "group":
+		var vv *Group
+		if currTypeName == "group" {
+			vv = v.IsShape.(*Group)
+		}
+		if err := cbor.Unmarshal(data, &vv); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Group for ShapeDefault: %v", err)
+		}
+
+		value = vv
+	case "polygon":
+		var vv *Polygon
+		if currTypeName == "polygon" {
+			vv = v.IsShape.(*Polygon)
+		}
+		if err := cbor.Unmarshal(data, &vv); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Polygon for ShapeDefault: %v", err)
+		}
+
+		value = vv
+	case "rectangle":
+		if currTypeName == "rectangle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Rectangle)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeDefault: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Rectangle)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeDefault: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Rectangle
+			if err := cbor.Unmarshal(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeDefault: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeDefault: %v", typeName)
+	}
+
+	*v = ShapeDefault{
+		IsShape: value,
+	}
+
+	return nil
+}
@@ -194,7 +194,7 @@ func (v *ShapeDefault) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
 			value = vv
 		}
 	default:
-		return fmt.Errorf("polygen: unknown subtype for ShapeDefault: %s", typeName)
+		return fmt.Errorf("polygen: unknown subtype for ShapeDefault: %v", typeName)
 	}
 
 	*v = ShapeDefault{
@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestShapeDeprecated(t *testing.T) {
+	var seen []string
+
+	ShapeDeprecatedDeprecatedHook = func(typ string, typeName string) {
+		seen = append(seen, typ+":"+typeName)
+	}
+	defer func() { ShapeDeprecatedDeprecatedHook = nil }()
+
+	var got []ShapeDeprecated
+	if err := json.Unmarshal([]byte(`[{"type":"circle"},{"type":"empty"},{"type":"polygon","Labels":["a"]},{"type":"empty"}]`), &got); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+
+	want := []ShapeDeprecated{
+		{IsShape: Circle{}},
+		{IsShape: Empty{}},
+		{IsShape: &Polygon{Labels: []string{"a"}}},
+		{IsShape: Empty{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalJSON() = %+v, want %+v", got, want)
+	}
+
+	wantSeen := []string{"ShapeDeprecated:empty", "ShapeDeprecated:polygon", "ShapeDeprecated:empty"}
+	if !reflect.DeepEqual(seen, wantSeen) {
+		t.Errorf("hook calls = %v, want %v", seen, wantSeen)
+	}
+
+	// Marshaling is not reported
+	if _, err := json.Marshal(ShapeDeprecated{IsShape: Empty{}}); err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if len(seen) != len(wantSeen) {
+		t.Errorf("hook calls = %v after marshaling, want %v", seen, wantSeen)
+	}
+}
@@ -0,0 +1,122 @@
+// Code generated by polygen; DO NOT EDIT.
+
+package tests
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// MarshalXML encodes the subtype as an element named after its type name, replacing the name of start.
+func (v ShapeElement) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if v.IsShape == nil {
+		return nil
+	}
+
+	typeName, _, err := _ShapeElementGetType(v.IsShape)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot get subtype to marshal for ShapeElement: %v", err)
+	}
+
+	start.Name.Local = typeName
+
+	if err := e.EncodeElement(v.IsShape, start); err != nil {
+		return fmt.Errorf("polygen: cannot marshal IsShape for ShapeElement: %v", err)
+	}
+
+	return nil
+}
+
+// UnmarshalXML decodes the subtype named by the element name of start.
+func (v *ShapeElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeElementGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeElement: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	typeName := start.Name.Local
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeElement: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeElement: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := d.DecodeElement(&vv, &start); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeElement: %v", err)
+			}
+
+			value = vv
+		}
+	case "polygon":
+		var vv *Polygon
+		if currTypeName == "polygon" {
+			vv = v.IsShape.(*Polygon)
+		}
+		if err := d.DecodeElement(&vv, &start); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Polygon for ShapeElement: %v", err)
+		}
+
+		value = vv
+	case "rectangle":
+		if currTypeName == "rectangle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Rectangle)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeElement: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Rectangle)
+				if err := d.DecodeElement(&vv, &start); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeElement: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Rectangle
+			if err := d.DecodeElement(&vv, &start); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeElement: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeElement: %v", typeName)
+	}
+
+	*v = ShapeElement{
+		IsShape: value,
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Code generated by polygen; DO NOT EDIT.
+
+//go:build go1.25 && goexperiment.jsonv2
+
+package tests
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+)
+
+func (v ShapeFielded) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if v.IsShape == nil {
+		return enc.WriteValue([]byte("null"))
+	}
+
+	typeName, _, err := _ShapeFieldedGetType(v.IsShape)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot get subtype to marshal for ShapeFielded: %v", err)
+	}
+
+	// The subtype carries the discriminator in its own field, so it is marshaled as is
+	impl, err := _ShapeFieldedSetDiscriminatorField(v.IsShape, typeName, true)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot marshal IsShape for ShapeFielded: %v", err)
+	}
+
+	implData, err := json.Marshal(impl, enc.Options())
+	if err != nil {
+		return fmt.Errorf("polygen: cannot marshal IsShape for ShapeFielded: %v", err)
+	}
+
+	return enc.WriteValue(implData)
+}
+
+func (v *ShapeFielded) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeFieldedGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeFielded: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	fullData := &struct {
+		TypeName string         `json:"kind"`
+		Data     jsontext.Value `json:",unknown"`
+	}{
+		TypeName: currTypeName,
+		Data:     []byte("{}"),
+	}
+
+	if err := json.UnmarshalDecode(dec, &fullData, json.RejectUnknownMembers(false)); err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal: %v", err)
+	}
+
+	if fullData == nil {
+		*v = ShapeFielded{}
+
+		return nil
+	}
+
+	if fullData.TypeName == "" {
+		fullData.TypeName = "arc"
+	}
+
+	typeName := fullData.TypeName
+
+	var value IsShape
+
+	switch typeName {
+	case "arc":
+		if currTypeName == "arc" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Arc)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Arc for ShapeFielded: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Arc)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Arc for ShapeFielded: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Arc
+			if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Arc for ShapeFielded: %v", err)
+			}
+
+			value = vv
+		}
+	case "line":
+		var vv *Line
+		if currTypeName == "line" {
+			vv = v.IsShape.(*Line)
+		}
+
+		if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Line for ShapeFielded: %v", err)
+		}
+
+		value = vv
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeFielded: %v", typeName)
+	}
+
+	// The discriminator field of the subtype must agree with the selected subtype
+	checked, err := _ShapeFieldedSetDiscriminatorField(value, typeName, false)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal IsShape for ShapeFielded: %v", err)
+	}
+
+	value = checked
+
+	*v = ShapeFielded{
+		IsShape: value,
+	}
+
+	return nil
+}
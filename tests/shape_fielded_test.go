@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestShapeFielded(t *testing.T) {
+	t.Run("marshal", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			shape   ShapeFielded
+			want    string
+			wantErr bool
+		}{
+			{
+				name:  "populate field",
+				shape: ShapeFielded{IsShape: Arc{Angle: 90}},
+				want:  `{"kind":"arc","Angle":90}`,
+			},
+			{
+				name:  "populate pointer field",
+				shape: ShapeFielded{IsShape: &Line{Length: 2}},
+				want:  `{"kind":"line","Length":2}`,
+			},
+			{
+				name:  "matching field",
+				shape: ShapeFielded{IsShape: Arc{Kind: "arc", Angle: 90}},
+				want:  `{"kind":"arc","Angle":90}`,
+			},
+			{
+				name:    "mismatching field",
+				shape:   ShapeFielded{IsShape: &Line{Kind: "arc"}},
+				wantErr: true,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := json.Marshal(tt.shape)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err == nil && string(got) != tt.want {
+					t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("marshal does not change the value", func(t *testing.T) {
+		line := &Line{Length: 2}
+		if _, err := json.Marshal(ShapeFielded{IsShape: line}); err != nil {
+			t.Fatalf("MarshalJSON() error = %v", err)
+		}
+		if line.Kind != "" {
+			t.Errorf("MarshalJSON() changed the subtype field to %q", line.Kind)
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			json    string
+			want    ShapeFielded
+			wantErr bool
+		}{
+			{
+				name: "arc",
+				json: `{"kind":"arc","Angle":90}`,
+				want: ShapeFielded{IsShape: Arc{Kind: "arc", Angle: 90}},
+			},
+			{
+				name: "line",
+				json: `{"Length":2,"kind":"line"}`,
+				want: ShapeFielded{IsShape: &Line{Kind: "line", Length: 2}},
+			},
+			{
+				name: "default subtype populates field",
+				json: `{"Angle":90}`,
+				want: ShapeFielded{IsShape: Arc{Kind: "arc", Angle: 90}},
+			},
+			{
+				name:    "strict",
+				json:    `{"kind":"arc","Angle":90,"extra":true}`,
+				wantErr: true,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// ShapeFielded is strict, the decoder makes it so for jsonv2 as well
+				var got ShapeFielded
+				dec := json.NewDecoder(bytes.NewReader([]byte(tt.json)))
+				dec.DisallowUnknownFields()
+				err := dec.Decode(&got)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err == nil && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("update with mismatching field", func(t *testing.T) {
+		got := ShapeFielded{IsShape: Arc{Kind: "line"}}
+		if err := json.Unmarshal([]byte(`{"Angle":90}`), &got); err == nil {
+			t.Error("UnmarshalJSON() expected error")
+		}
+	})
+}
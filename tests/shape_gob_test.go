@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShapeValidatedGob(t *testing.T) {
+	RegisterShapeValidatedGob()
+
+	type message struct {
+		Shape  ShapeValidated
+		Shapes []IsShape
+	}
+
+	tests := []struct {
+		name  string
+		value message
+	}{
+		{
+			name: "value",
+			value: message{
+				Shape:  ShapeValidated{IsShape: Rectangle{Width: 1, Height: 2}},
+				Shapes: []IsShape{Circle{Radius: 5}, &Polygon{Labels: []string{"a"}}},
+			},
+		},
+		{
+			name: "pointer",
+			value: message{
+				Shape: ShapeValidated{IsShape: &Polygon{Labels: []string{"a", "b"}}},
+			},
+		},
+		{
+			name:  "nil",
+			value: message{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(tt.value); err != nil {
+				t.Fatalf("GobEncode() error = %v", err)
+			}
+
+			var got message
+			if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+				t.Fatalf("GobDecode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("GobDecode() = %+v, want %+v", got, tt.value)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(ShapeValidated{IsShape: Rectangle{Width: -1}}); err != nil {
+			t.Fatalf("GobEncode() error = %v", err)
+		}
+
+		var got ShapeValidated
+		err := gob.NewDecoder(&buf).Decode(&got)
+		if want := "polygen: invalid tests.Rectangle for ShapeValidated: negative size -1x0"; err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("GobDecode() error = %v, want %q", err, want)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+// Code generated by polygen; DO NOT EDIT.
+
+//go:build go1.25 && goexperiment.jsonv2
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+)
+
+func (v ShapeKinded) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if v.IsShape == nil {
+		return enc.WriteValue([]byte("null"))
+	}
+
+	// Marshal the implementation first to get its fields
+	implData, err := json.Marshal(v.IsShape, enc.Options())
+	if err != nil {
+		return fmt.Errorf("polygen: cannot marshal IsShape for ShapeKinded: %v", err)
+	}
+
+	if bytes.Equal(implData, []byte("null")) {
+		return enc.WriteValue(implData)
+	}
+
+	typeName, _, err := _ShapeKindedGetType(v.IsShape)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot get subtype to marshal for ShapeKinded: %v", err)
+	}
+
+	typeNameData, err := json.Marshal(typeName, enc.Options())
+	if err != nil {
+		return fmt.Errorf("polygen: cannot marshal discriminator kind for ShapeKinded: %v", err)
+	}
+
+	// If it's an empty object, just return discriminator
+	if bytes.Equal(implData, []byte("{}")) {
+		return enc.WriteValue([]byte(`{"kind":` + string(typeNameData) + `}`))
+	}
+
+	if len(implData) == 0 || implData[0] != '{' {
+		return fmt.Errorf("polygen: expected JSON object for IsShape (%T), got %s", v.IsShape, implData)
+	}
+
+	// Otherwise, combine discriminator with implementation fields
+	var buf bytes.Buffer
+
+	buf.Grow(len(`{"kind":,`) + len(typeNameData) + len(implData) - 1)
+	buf.WriteString(`{"kind":`)
+	buf.Write(typeNameData)
+	buf.WriteString(`,`)
+	buf.Write(implData[1:])
+
+	return enc.WriteValue(buf.Bytes())
+}
+
+func (v *ShapeKinded) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	var (
+		currTypeName      ShapeKind
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeKindedGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeKinded: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	fullData := &struct {
+		TypeName ShapeKind      `json:"kind"`
+		Data     jsontext.Value `json:",unknown"`
+	}{
+		TypeName: currTypeName,
+		Data:     []byte("{}"),
+	}
+
+	if err := json.UnmarshalDecode(dec, &fullData, json.RejectUnknownMembers(false)); err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal: %v", err)
+	}
+
+	if fullData == nil {
+		*v = ShapeKinded{}
+
+		return nil
+	}
+
+	if fullData.TypeName == *new(ShapeKind) {
+		return errors.New("polygen: missing discriminator kind for ShapeKinded")
+	}
+
+	typeName := fullData.TypeName
+
+	var value IsShape
+
+	switch typeName {
+	case ShapeKindCircle:
+		if currTypeName == ShapeKindCircle {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeKinded: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeKinded: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeKinded: %v", err)
+			}
+
+			value = vv
+		}
+	case ShapeKindRectangle:
+		if currTypeName == ShapeKindRectangle {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Rectangle)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeKinded: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Rectangle)
+				if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeKinded: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Rectangle
+			if err := json.Unmarshal(fullData.Data, &vv, dec.Options()); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeKinded: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeKinded: %v", typeName)
+	}
+
+	*v = ShapeKinded{
+		IsShape: value,
+	}
+
+	return nil
+}
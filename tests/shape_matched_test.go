@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ykalchevskiy/polygen/gen"
+)
+
+func TestShapeMatched(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    ShapeMatched
+		wantErr bool
+	}{
+		{
+			name: "canonical",
+			json: `{"type":"circle","Radius":5}`,
+			want: ShapeMatched{IsShape: Circle{Radius: 5}},
+		},
+		{
+			name: "upper case",
+			json: `{"type":"CIRCLE","Radius":5}`,
+			want: ShapeMatched{IsShape: Circle{Radius: 5}},
+		},
+		{
+			name: "pascal case",
+			json: `{"type":"ShapeGroup","Name":"g"}`,
+			want: ShapeMatched{IsShape: &Group{Name: "g"}},
+		},
+		{
+			name: "snake case",
+			json: `{"type":"SHAPE_GROUP","Name":"g"}`,
+			want: ShapeMatched{IsShape: &Group{Name: "g"}},
+		},
+		{
+			name: "camel case",
+			json: `{"type":"shapeGroup","Name":"g"}`,
+			want: ShapeMatched{IsShape: &Group{Name: "g"}},
+		},
+		{
+			name:    "words run together",
+			json:    `{"type":"shapegroup","Name":"g"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown",
+			json:    `{"type":"square"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ShapeMatched
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("update", func(t *testing.T) {
+		got := ShapeMatched{IsShape: Circle{Radius: 5}}
+		if err := json.Unmarshal([]byte(`{"type":"Circle"}`), &got); err != nil {
+			t.Fatalf("UnmarshalJSON() error = %v", err)
+		}
+		if want := (ShapeMatched{IsShape: Circle{Radius: 5}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalJSON() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("marshal is canonical", func(t *testing.T) {
+		got, err := json.Marshal(ShapeMatched{IsShape: &Group{Name: "g"}})
+		if err != nil {
+			t.Fatalf("MarshalJSON() error = %v", err)
+		}
+		if want := `{"type":"shape-group","Name":"g","Attributes":null}`; string(got) != want {
+			t.Errorf("MarshalJSON() = %s, want %s", got, want)
+		}
+	})
+}
+
+// The generated normalizer must agree with the keys the generator derives from the subtype names.
+func TestShapeMatchedNormalizeTypeName(t *testing.T) {
+	cfg := &gen.Config{DiscriminatorMatch: gen.DiscriminatorMatchNormalized}
+
+	for _, name := range []string{
+		"",
+		"circle",
+		"CIRCLE",
+		"shape-group",
+		"shape_group",
+		"SHAPE_GROUP",
+		"ShapeGroup",
+		"shapeGroup",
+		"HTTPServer",
+		"http-server",
+		"HTTP_Server",
+		"Shape2D",
+		"shape-2d",
+		"shape_2_d",
+		"version2Test",
+		"a-_b",
+		"-leading",
+		"trailing_",
+		"ÜberGröße",
+	} {
+		if got, want := _ShapeMatchedNormalizeTypeName(name), cfg.DiscriminatorMatchKey(name); got != want {
+			t.Errorf("_ShapeMatchedNormalizeTypeName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Code generated by polygen; DO NOT EDIT.
+
+package tests
+
+import (
+	"fmt"
+
+	pb "github.com/ykalchevskiy/polygen/tests/protopb"
+)
+
+// ShapeMessageToProto converts v to its protobuf message, setting the field of the subtype oneof of its subtype.
+// A nil subtype leaves the oneof unset.
+func ShapeMessageToProto(v ShapeMessage) (*pb.ShapeMessage, error) {
+	m := &pb.ShapeMessage{}
+
+	switch vv := v.IsShape.(type) {
+	case nil:
+		return m, nil
+	case Circle:
+		m.Subtype = &pb.ShapeMessage_Circle{Circle: _ShapeMessageToProtoCircle(vv)}
+	case *Circle:
+		if vv == nil {
+			return m, nil
+		}
+
+		m.Subtype = &pb.ShapeMessage_Circle{Circle: _ShapeMessageToProtoCircle(*vv)}
+	case Empty:
+		m.Subtype = &pb.ShapeMessage_Empty{Empty: _ShapeMessageToProtoEmpty(vv)}
+	case *Empty:
+		if vv == nil {
+			return m, nil
+		}
+
+		m.Subtype = &pb.ShapeMessage_Empty{Empty: _ShapeMessageToProtoEmpty(*vv)}
+	case Label:
+		m.Subtype = &pb.ShapeMessage_Label{Label: _ShapeMessageToProtoLabel(vv)}
+	case *Label:
+		if vv == nil {
+			return m, nil
+		}
+
+		m.Subtype = &pb.ShapeMessage_Label{Label: _ShapeMessageToProtoLabel(*vv)}
+	case *Line:
+		if vv == nil {
+			return m, nil
+		}
+
+		m.Subtype = &pb.ShapeMessage_Line{Line: _ShapeMessageToProtoLine(*vv)}
+	case *Polygon:
+		if vv == nil {
+			return m, nil
+		}
+
+		m.Subtype = &pb.ShapeMessage_Polygon{Polygon: _ShapeMessageToProtoPolygon(*vv)}
+	case Rectangle:
+		m.Subtype = &pb.ShapeMessage_Rectangle{Rectangle: _ShapeMessageToProtoRectangle(vv)}
+	case *Rectangle:
+		if vv == nil {
+			return m, nil
+		}
+
+		m.Subtype = &pb.ShapeMessage_Rectangle{Rectangle: _ShapeMessageToProtoRectangle(*vv)}
+	default:
+		return nil, fmt.Errorf("polygen: unknown subtype for ShapeMessage: %T", vv)
+	}
+
+	return m, nil
+}
+
+// ShapeMessageFromProto converts the protobuf message m to ShapeMessage, holding the subtype of the field set in its subtype oneof.
+// An unset oneof or a nil message gives a nil subtype.
+func ShapeMessageFromProto(m *pb.ShapeMessage) (ShapeMessage, error) {
+	var value IsShape
+
+	switch mm := m.GetSubtype().(type) {
+	case nil:
+		return ShapeMessage{}, nil
+	case *pb.ShapeMessage_Circle:
+		value = _ShapeMessageFromProtoCircle(mm.Circle)
+	case *pb.ShapeMessage_Empty:
+		value = _ShapeMessageFromProtoEmpty(mm.Empty)
+	case *pb.ShapeMessage_Label:
+		value = _ShapeMessageFromProtoLabel(mm.Label)
+	case *pb.ShapeMessage_Line:
+		vv := _ShapeMessageFromProtoLine(mm.Line)
+		value = &vv
+	case *pb.ShapeMessage_Polygon:
+		vv := _ShapeMessageFromProtoPolygon(mm.Polygon)
+		value = &vv
+	case *pb.ShapeMessage_Rectangle:
+		value = _ShapeMessageFromProtoRectangle(mm.Rectangle)
+	default:
+		return ShapeMessage{}, fmt.Errorf("polygen: unknown protobuf subtype for ShapeMessage: %T", mm)
+	}
+
+	return ShapeMessage{
+		IsShape: value,
+	}, nil
+}
+
+func _ShapeMessageToProtoCircle(v Circle) *pb.Circle {
+	m := &pb.Circle{}
+	m.Radius = v.Radius
+
+	return m
+}
+
+func _ShapeMessageFromProtoCircle(m *pb.Circle) Circle {
+	var v Circle
+	if m == nil {
+		return v
+	}
+	v.Radius = m.Radius
+
+	return v
+}
+
+func _ShapeMessageToProtoEmpty(v Empty) *pb.Empty {
+	m := &pb.Empty{}
+
+	return m
+}
+
+func _ShapeMessageFromProtoEmpty(m *pb.Empty) Empty {
+	var v Empty
+	if m == nil {
+		return v
+	}
+
+	return v
+}
+
+func _ShapeMessageToProtoLabel(v Label) *pb.Label {
+	m := &pb.Label{}
+	m.Text = v.Text
+	m.Meta = _ShapeMessageToProtoLabelMeta(v.Meta)
+
+	return m
+}
+
+func _ShapeMessageFromProtoLabel(m *pb.Label) Label {
+	var v Label
+	if m == nil {
+		return v
+	}
+	v.Text = m.Text
+	v.Meta = _ShapeMessageFromProtoLabelMeta(m.Meta)
+
+	return v
+}
+
+func _ShapeMessageToProtoLabelMeta(v struct {
+	Author string "json:\"author,omitempty\""
+}) *pb.LabelMeta {
+	m := &pb.LabelMeta{}
+	m.Author = v.Author
+
+	return m
+}
+
+func _ShapeMessageFromProtoLabelMeta(m *pb.LabelMeta) struct {
+	Author string "json:\"author,omitempty\""
+} {
+	var v struct {
+		Author string "json:\"author,omitempty\""
+	}
+	if m == nil {
+		return v
+	}
+	v.Author = m.Author
+
+	return v
+}
+
+func _ShapeMessageToProtoLine(v Line) *pb.Line {
+	m := &pb.Line{}
+	m.Kind = v.Kind
+	m.Length = v.Length
+
+	return m
+}
+
+func _ShapeMessageFromProtoLine(m *pb.Line) Line {
+	var v Line
+	if m == nil {
+		return v
+	}
+	v.Kind = m.Kind
+	v.Length = m.Length
+
+	return v
+}
+
+func _ShapeMessageToProtoPolygon(v Polygon) *pb.Polygon {
+	m := &pb.Polygon{}
+	if v.Points != nil {
+		m.Points = make([]*pb.PolygonPoints, len(v.Points))
+		for i, e := range v.Points {
+			m.Points[i] = _ShapeMessageToProtoPolygonPoints(e)
+		}
+	}
+	if v.Labels != nil {
+		m.Labels = make([]string, len(v.Labels))
+		for i, e := range v.Labels {
+			m.Labels[i] = e
+		}
+	}
+
+	return m
+}
+
+func _ShapeMessageFromProtoPolygon(m *pb.Polygon) Polygon {
+	var v Polygon
+	if m == nil {
+		return v
+	}
+	if m.Points != nil {
+		v.Points = make([]struct {
+			X float64
+			Y float64
+		}, len(m.Points))
+		for i, e := range m.Points {
+			v.Points[i] = _ShapeMessageFromProtoPolygonPoints(e)
+		}
+	}
+	if m.Labels != nil {
+		v.Labels = make([]string, len(m.Labels))
+		for i, e := range m.Labels {
+			v.Labels[i] = e
+		}
+	}
+
+	return v
+}
+
+func _ShapeMessageToProtoPolygonPoints(v struct {
+	X float64
+	Y float64
+}) *pb.PolygonPoints {
+	m := &pb.PolygonPoints{}
+	m.X = v.X
+	m.Y = v.Y
+
+	return m
+}
+
+func _ShapeMessageFromProtoPolygonPoints(m *pb.PolygonPoints) struct {
+	X float64
+	Y float64
+} {
+	var v struct {
+		X float64
+		Y float64
+	}
+	if m == nil {
+		return v
+	}
+	v.X = m.X
+	v.Y = m.Y
+
+	return v
+}
+
+func _ShapeMessageToProtoRectangle(v Rectangle) *pb.Rectangle {
+	m := &pb.Rectangle{}
+	m.Width = v.Width
+	m.Height = v.Height
+	m.Style = _ShapeMessageToProtoRectangleStyle(v.Style)
+
+	return m
+}
+
+func _ShapeMessageFromProtoRectangle(m *pb.Rectangle) Rectangle {
+	var v Rectangle
+	if m == nil {
+		return v
+	}
+	v.Width = m.Width
+	v.Height = m.Height
+	v.Style = _ShapeMessageFromProtoRectangleStyle(m.Style)
+
+	return v
+}
+
+func _ShapeMessageToProtoRectangleStyle(v struct {
+	Color string
+	Fill  bool
+}) *pb.RectangleStyle {
+	m := &pb.RectangleStyle{}
+	m.Color = v.Color
+	m.Fill = v.Fill
+
+	return m
+}
+
+func _ShapeMessageFromProtoRectangleStyle(m *pb.RectangleStyle) struct {
+	Color string
+	Fill  bool
+} {
+	var v struct {
+		Color string
+		Fill  bool
+	}
+	if m == nil {
+		return v
+	}
+	v.Color = m.Color
+	v.Fill = m.Fill
+
+	return v
+}
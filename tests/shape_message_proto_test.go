@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ykalchevskiy/polygen/tests/protopb"
+)
+
+func TestShapeMessageProtoRoundTrip(t *testing.T) {
+	rectangle := Rectangle{Width: 1, Height: 2}
+	rectangle.Style.Color = "red"
+	rectangle.Style.Fill = true
+
+	label := Label{Text: "hi"}
+	label.Meta.Author = "me"
+
+	polygon := &Polygon{
+		Points: []struct {
+			X float64
+			Y float64
+		}{{X: 1, Y: 2}, {X: 3, Y: 4}},
+		Labels: []string{"a", "b"},
+	}
+
+	for _, tt := range []struct {
+		name  string
+		shape ShapeMessage
+	}{
+		{name: "circle", shape: ShapeMessage{IsShape: Circle{Radius: 5}}},
+		{name: "rectangle", shape: ShapeMessage{IsShape: rectangle}},
+		{name: "polygon", shape: ShapeMessage{IsShape: polygon}},
+		{name: "empty polygon", shape: ShapeMessage{IsShape: &Polygon{}}},
+		{name: "empty", shape: ShapeMessage{IsShape: Empty{}}},
+		{name: "label", shape: ShapeMessage{IsShape: label}},
+		{name: "line", shape: ShapeMessage{IsShape: &Line{Kind: "line", Length: 3}}},
+		{name: "nil", shape: ShapeMessage{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ShapeMessageToProto(tt.shape)
+			if err != nil {
+				t.Fatalf("ShapeMessageToProto() error = %v", err)
+			}
+
+			got, err := ShapeMessageFromProto(m)
+			if err != nil {
+				t.Fatalf("ShapeMessageFromProto() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.shape) {
+				t.Errorf("ShapeMessageFromProto() = %+v, want %+v", got, tt.shape)
+			}
+		})
+	}
+}
+
+func TestShapeMessageToProto(t *testing.T) {
+	m, err := ShapeMessageToProto(ShapeMessage{IsShape: &Circle{Radius: 5}})
+	if err != nil {
+		t.Fatalf("ShapeMessageToProto() error = %v", err)
+	}
+
+	want := &protopb.ShapeMessage{Subtype: &protopb.ShapeMessage_Circle{Circle: &protopb.Circle{Radius: 5}}}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("ShapeMessageToProto() = %+v, want %+v", m, want)
+	}
+
+	if _, err := ShapeMessageToProto(ShapeMessage{IsShape: Arc{}}); err == nil {
+		t.Error("ShapeMessageToProto() error = nil, want unknown subtype")
+	}
+}
+
+func TestShapeMessageFromProto(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		m    *protopb.ShapeMessage
+		want ShapeMessage
+	}{
+		{
+			name: "nil message",
+			m:    nil,
+			want: ShapeMessage{},
+		},
+		{
+			name: "unset oneof",
+			m:    &protopb.ShapeMessage{},
+			want: ShapeMessage{},
+		},
+		{
+			name: "nil subtype message",
+			m:    &protopb.ShapeMessage{Subtype: &protopb.ShapeMessage_Line{}},
+			want: ShapeMessage{IsShape: &Line{}},
+		},
+		{
+			name: "nil nested message",
+			m:    &protopb.ShapeMessage{Subtype: &protopb.ShapeMessage_Rectangle{Rectangle: &protopb.Rectangle{Width: 1}}},
+			want: ShapeMessage{IsShape: Rectangle{Width: 1}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ShapeMessageFromProto(tt.m)
+			if err != nil {
+				t.Fatalf("ShapeMessageFromProto() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ShapeMessageFromProto() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
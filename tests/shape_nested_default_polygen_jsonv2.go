@@ -0,0 +1,154 @@
+// Code generated by polygen; DO NOT EDIT.
+
+//go:build go1.25 && goexperiment.jsonv2
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+)
+
+func (v ShapeNestedDefault) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if v.IsShape == nil {
+		return enc.WriteValue([]byte("null"))
+	}
+
+	// Marshal the implementation first to get its fields
+	implData, err := json.Marshal(v.IsShape, enc.Options())
+	if err != nil {
+		return fmt.Errorf("polygen: cannot marshal IsShape for ShapeNestedDefault: %v", err)
+	}
+
+	if bytes.Equal(implData, []byte("null")) {
+		return enc.WriteValue(implData)
+	}
+
+	typeName, _, err := _ShapeNestedDefaultGetType(v.IsShape)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot get subtype to marshal for ShapeNestedDefault: %v", err)
+	}
+
+	typeNameData, err := json.Marshal(typeName, enc.Options())
+	if err != nil {
+		return fmt.Errorf("polygen: cannot marshal discriminator /meta/type for ShapeNestedDefault: %v", err)
+	}
+
+	if len(implData) == 0 || implData[0] != '{' {
+		return fmt.Errorf("polygen: expected JSON object for IsShape (%T), got %s", v.IsShape, implData)
+	}
+
+	// Merge discriminator into the nested object, creating it if missing
+	data, err := _ShapeNestedDefaultInsertDiscriminator(implData, []string{"meta", "type"}, typeNameData)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot marshal discriminator /meta/type for ShapeNestedDefault: %v", err)
+	}
+
+	return enc.WriteValue(data)
+}
+
+func (v *ShapeNestedDefault) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeNestedDefaultGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeNestedDefault: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	raw, err := dec.ReadValue()
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal: %v", err)
+	}
+
+	if raw.Kind() == 'n' {
+		*v = ShapeNestedDefault{}
+
+		return nil
+	}
+
+	// First extract the type field, so that it does not get in the way of decoding the subtype
+	typeName := currTypeName
+
+	data, err := _ShapeNestedDefaultExtractDiscriminator(raw, []string{"meta", "type"}, &typeName)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal discriminator /meta/type for ShapeNestedDefault: %v", err)
+	}
+
+	if typeName == "" {
+		typeName = "circle"
+	}
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := json.Unmarshal(data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNestedDefault: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := json.Unmarshal(data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNestedDefault: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := json.Unmarshal(data, &vv, dec.Options()); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNestedDefault: %v", err)
+			}
+
+			value = vv
+		}
+	case "label":
+		if currTypeName == "label" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Label)
+				if err := json.Unmarshal(data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNestedDefault: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Label)
+				if err := json.Unmarshal(data, &vv, dec.Options()); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNestedDefault: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Label
+			if err := json.Unmarshal(data, &vv, dec.Options()); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNestedDefault: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeNestedDefault: %v", typeName)
+	}
+
+	*v = ShapeNestedDefault{
+		IsShape: value,
+	}
+
+	return nil
+}
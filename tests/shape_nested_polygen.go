@@ -0,0 +1,381 @@
+// Code generated by polygen; DO NOT EDIT.
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	_ IsShape = Circle{}
+	_ IsShape = Empty{}
+	_ IsShape = Label{}
+)
+
+// _ShapeNestedTypeRegistry maps concrete types to their type names.
+var _ShapeNestedTypeRegistry = map[reflect.Type]string{
+	reflect.TypeOf((*Circle)(nil)).Elem(): "circle",
+	reflect.TypeOf((*Empty)(nil)).Elem():  "empty",
+	reflect.TypeOf((*Label)(nil)).Elem():  "label",
+}
+
+type ShapeNested struct {
+	IsShape
+}
+
+func (v ShapeNested) MarshalJSON() ([]byte, error) {
+	if v.IsShape == nil {
+		return []byte("null"), nil
+	}
+
+	// Marshal the implementation first to get its fields
+	implData, err := json.Marshal(v.IsShape)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape for ShapeNested: %v", err)
+	}
+
+	if bytes.Equal(implData, []byte("null")) {
+		return implData, nil
+	}
+
+	typeName, _, err := _ShapeNestedGetType(v.IsShape)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot get subtype to marshal for ShapeNested: %v", err)
+	}
+
+	typeNameData, err := json.Marshal(typeName)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal discriminator meta.type for ShapeNested: %v", err)
+	}
+
+	if len(implData) == 0 || implData[0] != '{' {
+		return nil, fmt.Errorf("polygen: expected JSON object for IsShape (%T), got %s", v.IsShape, implData)
+	}
+
+	// Merge discriminator into the nested object, creating it if missing
+	data, err := _ShapeNestedInsertDiscriminator(implData, []string{"meta", "type"}, typeNameData)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal discriminator meta.type for ShapeNested: %v", err)
+	}
+
+	return data, nil
+}
+
+func (v *ShapeNested) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*v = ShapeNested{}
+
+		return nil
+	}
+
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeNestedGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeNested: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	// First extract the type field, so that it does not get in the way of decoding the subtype
+	typeName := currTypeName
+
+	data, err := _ShapeNestedExtractDiscriminator(data, []string{"meta", "type"}, &typeName)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal discriminator meta.type for ShapeNested: %v", err)
+	}
+
+	if typeName == "" {
+		return errors.New("polygen: missing discriminator meta.type for ShapeNested")
+	}
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := struct {
+					*Circle
+				}{}
+				vv.Circle = v.IsShape.(*Circle)
+
+				decoder := json.NewDecoder(bytes.NewReader(data))
+				decoder.DisallowUnknownFields()
+
+				if err := decoder.Decode(&vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNested: %v", err)
+				}
+
+				value = vv.Circle
+			} else {
+				vv := struct {
+					Circle
+				}{}
+				vv.Circle = v.IsShape.(Circle)
+
+				decoder := json.NewDecoder(bytes.NewReader(data))
+				decoder.DisallowUnknownFields()
+
+				if err := decoder.Decode(&vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNested: %v", err)
+				}
+
+				value = vv.Circle
+			}
+		} else {
+			vv := struct {
+				Circle
+			}{}
+
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields()
+
+			if err := decoder.Decode(&vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNested: %v", err)
+			}
+
+			value = vv.Circle
+		}
+	case "empty":
+		if currTypeName == "empty" {
+			if currTypeAsPointer {
+				vv := struct {
+					*Empty
+				}{}
+				vv.Empty = v.IsShape.(*Empty)
+
+				decoder := json.NewDecoder(bytes.NewReader(data))
+				decoder.DisallowUnknownFields()
+
+				if err := decoder.Decode(&vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeNested: %v", err)
+				}
+
+				value = vv.Empty
+			} else {
+				vv := struct {
+					Empty
+				}{}
+				vv.Empty = v.IsShape.(Empty)
+
+				decoder := json.NewDecoder(bytes.NewReader(data))
+				decoder.DisallowUnknownFields()
+
+				if err := decoder.Decode(&vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeNested: %v", err)
+				}
+
+				value = vv.Empty
+			}
+		} else {
+			vv := struct {
+				Empty
+			}{}
+
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields()
+
+			if err := decoder.Decode(&vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeNested: %v", err)
+			}
+
+			value = vv.Empty
+		}
+	case "label":
+		if currTypeName == "label" {
+			if currTypeAsPointer {
+				vv := struct {
+					*Label
+				}{}
+				vv.Label = v.IsShape.(*Label)
+
+				decoder := json.NewDecoder(bytes.NewReader(data))
+				decoder.DisallowUnknownFields()
+
+				if err := decoder.Decode(&vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNested: %v", err)
+				}
+
+				value = vv.Label
+			} else {
+				vv := struct {
+					Label
+				}{}
+				vv.Label = v.IsShape.(Label)
+
+				decoder := json.NewDecoder(bytes.NewReader(data))
+				decoder.DisallowUnknownFields()
+
+				if err := decoder.Decode(&vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNested: %v", err)
+				}
+
+				value = vv.Label
+			}
+		} else {
+			vv := struct {
+				Label
+			}{}
+
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields()
+
+			if err := decoder.Decode(&vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNested: %v", err)
+			}
+
+			value = vv.Label
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeNested: %v", typeName)
+	}
+
+	*v = ShapeNested{
+		IsShape: value,
+	}
+
+	return nil
+}
+
+func _ShapeNestedGetType(v IsShape) (name string, asPointer bool, _ error) {
+	t := reflect.TypeOf(v)
+
+	typeName, ok := _ShapeNestedTypeRegistry[t]
+	if ok {
+		return typeName, false, nil
+	}
+
+	// A pointer can be manually used for a value type as it also implements the interface
+	if t.Kind() == reflect.Pointer {
+		typeName, ok = _ShapeNestedTypeRegistry[t.Elem()]
+		if ok {
+			return typeName, true, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("unknown subtype: %v", t)
+}
+
+// _ShapeNestedInsertDiscriminator inserts the encoded discriminator value at path into the JSON object data,
+// merging it into existing nested objects and creating missing ones.
+func _ShapeNestedInsertDiscriminator(data []byte, path []string, value []byte) ([]byte, error) {
+	key, err := json.Marshal(path[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(path) > 1 {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+
+		for decoder.More() {
+			name, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			var member json.RawMessage
+			if err := decoder.Decode(&member); err != nil {
+				return nil, err
+			}
+
+			if name != path[0] {
+				continue
+			}
+
+			end := int(decoder.InputOffset())
+			start := end - len(member)
+
+			if string(member) == "null" {
+				member = json.RawMessage("{}")
+			}
+
+			if member[0] != '{' {
+				return nil, fmt.Errorf("expected JSON object at %s, got %s", path[0], member)
+			}
+
+			nested, err := _ShapeNestedInsertDiscriminator(member, path[1:], value)
+			if err != nil {
+				return nil, err
+			}
+
+			result := make([]byte, 0, len(data)-len(member)+len(nested))
+			result = append(result, data[:start]...)
+			result = append(result, nested...)
+
+			return append(result, data[end:]...), nil
+		}
+
+		// The nested object is missing, so create it
+		value, err = _ShapeNestedInsertDiscriminator([]byte("{}"), path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+
+	buf.Grow(len(key) + len(value) + len(data) + 1)
+	buf.WriteByte('{')
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(value)
+	if rest := bytes.TrimSpace(data[1:]); len(rest) > 0 && rest[0] != '}' {
+		buf.WriteByte(',')
+	}
+	buf.Write(data[1:])
+
+	return buf.Bytes(), nil
+}
+
+// _ShapeNestedExtractDiscriminator decodes the discriminator at path in the JSON object data into typeName
+// and returns data without it, dropping nested objects left empty. typeName is kept if the path is missing.
+func _ShapeNestedExtractDiscriminator(data []byte, path []string, typeName *string) ([]byte, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	member, ok := object[path[0]]
+	if !ok {
+		return data, nil
+	}
+
+	if len(path) == 1 {
+		if err := json.Unmarshal(member, typeName); err != nil {
+			return nil, err
+		}
+
+		delete(object, path[0])
+	} else {
+		if string(member) == "null" {
+			return data, nil
+		}
+
+		nested, err := _ShapeNestedExtractDiscriminator(member, path[1:], typeName)
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Equal(nested, []byte("{}")) {
+			delete(object, path[0])
+		} else {
+			object[path[0]] = nested
+		}
+	}
+
+	return json.Marshal(object)
+}
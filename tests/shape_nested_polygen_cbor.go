@@ -0,0 +1,180 @@
+// Code generated by polygen; DO NOT EDIT.
+
+package tests
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ykalchevskiy/polygen/cbor"
+)
+
+var (
+	_ cbor.Marshaler   = ShapeNested{}
+	_ cbor.Unmarshaler = (*ShapeNested)(nil)
+)
+
+// MarshalCBOR encodes the subtype as a CBOR map with the meta.type key holding its type name first.
+func (v ShapeNested) MarshalCBOR() ([]byte, error) {
+	if v.IsShape == nil {
+		return cbor.Marshal(nil)
+	}
+
+	typeName, _, err := _ShapeNestedGetType(v.IsShape)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot get subtype to marshal for ShapeNested: %v", err)
+	}
+
+	impl := v.IsShape
+
+	implData, err := cbor.Marshal(impl)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape for ShapeNested: %v", err)
+	}
+
+	if cbor.IsNull(implData) {
+		return implData, nil
+	}
+
+	typeNameData, err := cbor.Marshal(typeName)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal discriminator meta.type for ShapeNested: %v", err)
+	}
+
+	data, err := cbor.InsertPath(implData, []string{"meta", "type"}, typeNameData)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape (%T) for ShapeNested: %v", v.IsShape, err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalCBOR decodes the subtype named by the meta.type key of the CBOR map.
+func (v *ShapeNested) UnmarshalCBOR(data []byte) error {
+	if cbor.IsNull(data) {
+		*v = ShapeNested{}
+
+		return nil
+	}
+
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeNestedGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeNested: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	// First extract the type key, so that it does not get in the way of decoding the subtype
+	typeNameData, data, err := cbor.ExtractPath(data, []string{"meta", "type"})
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal discriminator meta.type for ShapeNested: %v", err)
+	}
+
+	typeName := currTypeName
+	if typeNameData != nil {
+		if err := cbor.Unmarshal(typeNameData, &typeName); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal discriminator meta.type for ShapeNested: %v", err)
+		}
+	}
+
+	if typeName == "" {
+		return errors.New("polygen: missing discriminator meta.type for ShapeNested")
+	}
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNested: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNested: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNested: %v", err)
+			}
+
+			value = vv
+		}
+	case "empty":
+		if currTypeName == "empty" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Empty)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeNested: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Empty)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeNested: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Empty
+			if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeNested: %v", err)
+			}
+
+			value = vv
+		}
+	case "label":
+		if currTypeName == "label" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Label)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNested: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Label)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNested: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Label
+			if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNested: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeNested: %v", typeName)
+	}
+
+	*v = ShapeNested{
+		IsShape: value,
+	}
+
+	return nil
+}
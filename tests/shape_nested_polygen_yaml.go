@@ -0,0 +1,347 @@
+// Code generated by polygen; DO NOT EDIT.
+
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYAML encodes the subtype as a mapping with the meta.type key holding its type name.
+func (v ShapeNested) MarshalYAML() (any, error) {
+	if v.IsShape == nil {
+		return nil, nil
+	}
+
+	typeName, _, err := _ShapeNestedGetType(v.IsShape)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot get subtype to marshal for ShapeNested: %v", err)
+	}
+
+	impl := v.IsShape
+
+	var node yaml.Node
+	if err := node.Encode(impl); err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape for ShapeNested: %v", err)
+	}
+
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return nil, nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("polygen: expected YAML mapping for IsShape (%T), got %s", v.IsShape, node.ShortTag())
+	}
+
+	// An empty subtype is encoded in flow style, which should not apply once the discriminator is added
+	node.Style &^= yaml.FlowStyle
+
+	if err := _ShapeNestedInsertYAMLDiscriminator(&node, []string{"meta", "type"}, typeName); err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal discriminator meta.type for ShapeNested: %v", err)
+	}
+
+	return &node, nil
+}
+
+// UnmarshalYAML decodes the subtype named by the meta.type key of the mapping node.
+func (v *ShapeNested) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("polygen: expected YAML mapping for ShapeNested, got %s", node.ShortTag())
+	}
+
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeNestedGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeNested: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	// First extract the type key, so that it does not get in the way of decoding the subtype
+	typeName := currTypeName
+
+	node, err := _ShapeNestedExtractYAMLDiscriminator(node, []string{"meta", "type"}, &typeName)
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal discriminator meta.type for ShapeNested: %v", err)
+	}
+
+	if typeName == "" {
+		return errors.New("polygen: missing discriminator meta.type for ShapeNested")
+	}
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := _ShapeNestedDecodeYAML(node, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNested: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := _ShapeNestedDecodeYAML(node, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNested: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := _ShapeNestedDecodeYAML(node, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeNested: %v", err)
+			}
+
+			value = vv
+		}
+	case "empty":
+		if currTypeName == "empty" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Empty)
+				if err := _ShapeNestedDecodeYAML(node, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeNested: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Empty)
+				if err := _ShapeNestedDecodeYAML(node, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeNested: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Empty
+			if err := _ShapeNestedDecodeYAML(node, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeNested: %v", err)
+			}
+
+			value = vv
+		}
+	case "label":
+		if currTypeName == "label" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Label)
+				if err := _ShapeNestedDecodeYAML(node, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNested: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Label)
+				if err := _ShapeNestedDecodeYAML(node, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNested: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Label
+			if err := _ShapeNestedDecodeYAML(node, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Label for ShapeNested: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeNested: %v", typeName)
+	}
+
+	*v = ShapeNested{
+		IsShape: value,
+	}
+
+	return nil
+}
+
+// _ShapeNestedDecodeYAML decodes node into v, failing on unknown fields.
+func _ShapeNestedDecodeYAML(node *yaml.Node, v any) error {
+	// Only a yaml.Decoder rejects unknown fields, so the node is encoded first
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	return decoder.Decode(v)
+}
+
+// _ShapeNestedInsertYAMLDiscriminator sets the discriminator at path in the mapping node to typeName,
+// merging it into existing nested mappings and creating missing ones.
+func _ShapeNestedInsertYAMLDiscriminator(node *yaml.Node, path []string, typeName string) error {
+	var value yaml.Node
+
+	if len(path) == 1 {
+		if err := value.Encode(typeName); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			node.Content[i+1] = &value
+
+			return nil
+		}
+
+		member := node.Content[i+1]
+		if member.Kind == yaml.ScalarNode && member.ShortTag() == "!!null" {
+			*member = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+
+		if member.Kind != yaml.MappingNode {
+			return fmt.Errorf("expected YAML mapping at %s, got %s", path[0], member.ShortTag())
+		}
+
+		return _ShapeNestedInsertYAMLDiscriminator(member, path[1:], typeName)
+	}
+
+	// The key is missing, so create it
+	if len(path) > 1 {
+		value = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if err := _ShapeNestedInsertYAMLDiscriminator(&value, path[1:], typeName); err != nil {
+			return err
+		}
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}
+	node.Content = append([]*yaml.Node{key, &value}, node.Content...)
+
+	return nil
+}
+
+// _ShapeNestedExtractYAMLDiscriminator decodes the discriminator at path in the mapping node into typeName
+// and returns a copy of node without it, dropping nested mappings left empty. typeName is kept if the path is missing.
+// Merge keys (<<) are resolved first, so that a discriminator merged from an anchored mapping is found.
+func _ShapeNestedExtractYAMLDiscriminator(node *yaml.Node, path []string, typeName *string) (*yaml.Node, error) {
+	node, err := _ShapeNestedResolveYAMLMerges(node)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+
+		member := node.Content[i+1]
+		content := append([]*yaml.Node{}, node.Content[:i]...)
+
+		if len(path) == 1 {
+			if err := member.Decode(typeName); err != nil {
+				return nil, err
+			}
+		} else {
+			if member.Kind == yaml.AliasNode {
+				member = member.Alias
+			}
+
+			if member.Kind == yaml.ScalarNode && member.ShortTag() == "!!null" {
+				return node, nil
+			}
+
+			if member.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("expected YAML mapping at %s, got %s", path[0], member.ShortTag())
+			}
+
+			nested, err := _ShapeNestedExtractYAMLDiscriminator(member, path[1:], typeName)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(nested.Content) > 0 {
+				content = append(content, node.Content[i], nested)
+			}
+		}
+
+		extracted := *node
+		extracted.Content = append(content, node.Content[i+2:]...)
+
+		return &extracted, nil
+	}
+
+	return node, nil
+}
+
+// _ShapeNestedResolveYAMLMerges returns a copy of the mapping node with the mappings of its merge keys (<<) inlined,
+// as yaml.v3 does when decoding. Keys of the node take precedence over merged ones, and earlier merged
+// mappings over later ones. The node itself is returned if it has no merge keys.
+func _ShapeNestedResolveYAMLMerges(node *yaml.Node) (*yaml.Node, error) {
+	var (
+		content []*yaml.Node
+		merged  []*yaml.Node
+	)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if key.Kind != yaml.ScalarNode || key.Value != "<<" || key.ShortTag() != "!!merge" {
+			content = append(content, key, value)
+
+			continue
+		}
+
+		if value.Kind == yaml.SequenceNode {
+			merged = append(merged, value.Content...)
+		} else {
+			merged = append(merged, value)
+		}
+	}
+
+	if len(content) == len(node.Content) {
+		return node, nil
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < len(content); i += 2 {
+		seen[content[i].Value] = true
+	}
+
+	for _, m := range merged {
+		if m.Kind == yaml.AliasNode {
+			m = m.Alias
+		}
+
+		if m.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("expected YAML mapping to merge, got %s", m.ShortTag())
+		}
+
+		m, err := _ShapeNestedResolveYAMLMerges(m)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if !seen[m.Content[i].Value] {
+				seen[m.Content[i].Value] = true
+				content = append(content, m.Content[i], m.Content[i+1])
+			}
+		}
+	}
+
+	resolved := *node
+	resolved.Content = content
+
+	return &resolved, nil
+}
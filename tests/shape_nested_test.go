@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestShapeNested(t *testing.T) {
+	label := Label{Text: "hello"}
+	label.Meta.Author = "me"
+
+	t.Run("marshal", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			shape ShapeNested
+			want  string
+		}{
+			{
+				name:  "create nested object",
+				shape: ShapeNested{IsShape: Circle{Radius: 5}},
+				want:  `{"meta":{"type":"circle"},"Radius":5}`,
+			},
+			{
+				name:  "empty",
+				shape: ShapeNested{IsShape: Empty{}},
+				want:  `{"meta":{"type":"empty"}}`,
+			},
+			{
+				name:  "merge into nested object",
+				shape: ShapeNested{IsShape: label},
+				want:  `{"Text":"hello","meta":{"type":"label","author":"me"}}`,
+			},
+			{
+				name:  "merge into empty nested object",
+				shape: ShapeNested{IsShape: Label{Text: "hello"}},
+				want:  `{"Text":"hello","meta":{"type":"label"}}`,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := json.Marshal(tt.shape)
+				if err != nil {
+					t.Fatalf("MarshalJSON() error = %v", err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			json    string
+			want    ShapeNested
+			wantErr bool
+		}{
+			{
+				name: "circle",
+				json: `{"Radius":5,"meta":{"type":"circle"}}`,
+				want: ShapeNested{IsShape: Circle{Radius: 5}},
+			},
+			{
+				name: "label",
+				json: `{"meta":{"author":"me","type":"label"},"Text":"hello"}`,
+				want: ShapeNested{IsShape: label},
+			},
+			{
+				name:    "strict rejects other nested keys",
+				json:    `{"meta":{"type":"circle","author":"me"},"Radius":5}`,
+				wantErr: true,
+			},
+			{
+				name:    "top-level key is not the discriminator",
+				json:    `{"type":"circle","Radius":5}`,
+				wantErr: true,
+			},
+			{
+				name:    "missing nested object",
+				json:    `{"Radius":5}`,
+				wantErr: true,
+			},
+			{
+				name:    "unknown subtype",
+				json:    `{"meta":{"type":"square"}}`,
+				wantErr: true,
+			},
+			{
+				name: "null",
+				json: `null`,
+				want: ShapeNested{},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// ShapeNested is strict, the decoder makes it so for jsonv2 as well
+				var got ShapeNested
+				dec := json.NewDecoder(bytes.NewReader([]byte(tt.json)))
+				dec.DisallowUnknownFields()
+				err := dec.Decode(&got)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err == nil && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("roundtrip", func(t *testing.T) {
+		data, err := json.Marshal(ShapeNested{IsShape: label})
+		if err != nil {
+			t.Fatalf("MarshalJSON() error = %v", err)
+		}
+
+		var got ShapeNested
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("UnmarshalJSON() error = %v", err)
+		}
+		if want := (ShapeNested{IsShape: label}); !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalJSON() = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestShapeNestedDefault(t *testing.T) {
+	var got ShapeNestedDefault
+	if err := json.Unmarshal([]byte(`{"Radius":5}`), &got); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if want := (ShapeNestedDefault{IsShape: Circle{Radius: 5}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalJSON() = %+v, want %+v", got, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Text":"hello","meta":{"type":"label"}}`), &got); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if want := (ShapeNestedDefault{IsShape: Label{Text: "hello"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalJSON() = %+v, want %+v", got, want)
+	}
+}
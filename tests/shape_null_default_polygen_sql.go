@@ -0,0 +1,57 @@
+// Code generated by polygen; DO NOT EDIT.
+
+package tests
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	_ sql.Scanner   = (*ShapeNullDefault)(nil)
+	_ driver.Valuer = ShapeNullDefault{}
+)
+
+// Scan implements sql.Scanner, decoding a JSON column with the generated JSON methods.
+// SQL NULL is decoded like JSON null.
+func (v *ShapeNullDefault) Scan(src any) error {
+	var data []byte
+
+	switch src := src.(type) {
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	case nil:
+		data = []byte("null")
+	default:
+		return fmt.Errorf("polygen: cannot scan %T into ShapeNullDefault", src)
+	}
+
+	// Rows scanned into the same variable must not keep fields of the value held before
+	*v = ShapeNullDefault{}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("polygen: cannot scan ShapeNullDefault: %v", err)
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer, encoding v as JSON text with the generated JSON methods.
+// A nil subtype is stored as SQL NULL.
+func (v ShapeNullDefault) Value() (driver.Value, error) {
+	if v.IsShape == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot get value of ShapeNullDefault: %v", err)
+	}
+
+	// Text rather than []byte, which drivers may send as binary data that JSON columns reject
+	return string(data), nil
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestShapeNullDefault(t *testing.T) {
+	t.Run("unmarshal null", func(t *testing.T) {
+		got := ShapeNullDefault{IsShape: &Polygon{Labels: []string{"a"}}}
+		if err := json.Unmarshal([]byte(`null`), &got); err != nil {
+			t.Fatalf("UnmarshalJSON() error = %v", err)
+		}
+		if want := (ShapeNullDefault{IsShape: Circle{}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalJSON() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("unmarshal null field", func(t *testing.T) {
+		var got struct {
+			Shape ShapeNullDefault
+		}
+		if err := json.Unmarshal([]byte(`{"Shape":null}`), &got); err != nil {
+			t.Fatalf("UnmarshalJSON() error = %v", err)
+		}
+		if want := (ShapeNullDefault{IsShape: Circle{}}); !reflect.DeepEqual(got.Shape, want) {
+			t.Errorf("UnmarshalJSON() = %+v, want %+v", got.Shape, want)
+		}
+	})
+
+	t.Run("omitzero", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			shape ShapeNullDefault
+			want  string
+		}{
+			{
+				name:  "nil",
+				shape: ShapeNullDefault{},
+				want:  `{}`,
+			},
+			{
+				name:  "nil pointer",
+				shape: ShapeNullDefault{IsShape: (*Polygon)(nil)},
+				want:  `{}`,
+			},
+			{
+				name:  "value",
+				shape: ShapeNullDefault{IsShape: Circle{Radius: 1}},
+				want:  `{"Shape":{"type":"circle","Radius":1}}`,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := json.Marshal(struct {
+					Shape ShapeNullDefault `json:",omitzero"`
+				}{Shape: tt.shape})
+				if err != nil {
+					t.Fatalf("MarshalJSON() error = %v", err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestShapeNullError(t *testing.T) {
+	t.Run("marshal", func(t *testing.T) {
+		for _, shape := range []ShapeNullError{{}, {IsShape: (*Polygon)(nil)}} {
+			if _, err := json.Marshal(shape); err == nil {
+				t.Errorf("MarshalJSON(%+v) expected error", shape)
+			}
+		}
+
+		got, err := json.Marshal(ShapeNullError{IsShape: Circle{Radius: 1}})
+		if err != nil {
+			t.Fatalf("MarshalJSON() error = %v", err)
+		}
+		if want := `{"type":"circle","Radius":1}`; string(got) != want {
+			t.Errorf("MarshalJSON() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("unmarshal null", func(t *testing.T) {
+		var got ShapeNullError
+		if err := json.Unmarshal([]byte(`null`), &got); err == nil {
+			t.Error("UnmarshalJSON() expected error")
+		}
+
+		var field struct {
+			Shape ShapeNullError
+		}
+		if err := json.Unmarshal([]byte(`{"Shape":null}`), &field); err == nil {
+			t.Error("UnmarshalJSON() expected error for a null field")
+		}
+	})
+}
@@ -212,7 +212,7 @@ func (v *Shape) UnmarshalJSON(data []byte) error {
 			value = vv
 		}
 	default:
-		return fmt.Errorf("polygen: unknown subtype for Shape: %s", typeName)
+		return fmt.Errorf("polygen: unknown subtype for Shape: %v", typeName)
 	}
 
 	*v = Shape{
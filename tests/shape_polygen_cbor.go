@@ -0,0 +1,202 @@
+// Code generated by polygen; DO NOT EDIT.
+
+//go:build go1.20
+
+package tests
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ykalchevskiy/polygen/cbor"
+)
+
+var (
+	_ cbor.Marshaler   = Shape{}
+	_ cbor.Unmarshaler = (*Shape)(nil)
+)
+
+// MarshalCBOR encodes the subtype as a CBOR map with the type key holding its type name first.
+func (v Shape) MarshalCBOR() ([]byte, error) {
+	if v.IsShape == nil {
+		return cbor.Marshal(nil)
+	}
+
+	typeName, _, err := _ShapeGetType(v.IsShape)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot get subtype to marshal for Shape: %v", err)
+	}
+
+	impl := v.IsShape
+
+	implData, err := cbor.Marshal(impl)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape for Shape: %v", err)
+	}
+
+	if cbor.IsNull(implData) {
+		return implData, nil
+	}
+
+	typeNameData, err := cbor.Marshal(typeName)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal discriminator type for Shape: %v", err)
+	}
+
+	data, err := cbor.InsertPath(implData, []string{"type"}, typeNameData)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape (%T) for Shape: %v", v.IsShape, err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalCBOR decodes the subtype named by the type key of the CBOR map.
+func (v *Shape) UnmarshalCBOR(data []byte) error {
+	if cbor.IsNull(data) {
+		*v = Shape{}
+
+		return nil
+	}
+
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for Shape: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	// First extract the type key, so that it does not get in the way of decoding the subtype
+	typeNameData, data, err := cbor.ExtractPath(data, []string{"type"})
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal discriminator type for Shape: %v", err)
+	}
+
+	typeName := currTypeName
+	if typeNameData != nil {
+		if err := cbor.Unmarshal(typeNameData, &typeName); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal discriminator type for Shape: %v", err)
+		}
+	}
+
+	if typeName == "" {
+		return errors.New("polygen: missing discriminator type for Shape")
+	}
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for Shape: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for Shape: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := cbor.Unmarshal(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for Shape: %v", err)
+			}
+
+			value = vv
+		}
+	case "empty":
+		if currTypeName == "empty" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Empty)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for Shape: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Empty)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for Shape: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Empty
+			if err := cbor.Unmarshal(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Empty for Shape: %v", err)
+			}
+
+			value = vv
+		}
+	case "group":
+		var vv *Group
+		if currTypeName == "group" {
+			vv = v.IsShape.(*Group)
+		}
+		if err := cbor.Unmarshal(data, &vv); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Group for Shape: %v", err)
+		}
+
+		value = vv
+	case "polygon":
+		var vv *Polygon
+		if currTypeName == "polygon" {
+			vv = v.IsShape.(*Polygon)
+		}
+		if err := cbor.Unmarshal(data, &vv); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Polygon for Shape: %v", err)
+		}
+
+		value = vv
+	case "rectangle":
+		if currTypeName == "rectangle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Rectangle)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for Shape: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Rectangle)
+				if err := cbor.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for Shape: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Rectangle
+			if err := cbor.Unmarshal(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Rectangle for Shape: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for Shape: %v", typeName)
+	}
+
+	*v = Shape{
+		IsShape: value,
+	}
+
+	return nil
+}
@@ -0,0 +1,433 @@
+// Code generated by polygen; DO NOT EDIT.
+
+//go:build go1.20
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ShapeList is a list of Shape decoded one element at a time.
+type ShapeList []Shape
+
+// ShapeMap is a map of Shape by string keys decoded one entry at a time.
+type ShapeMap map[string]Shape
+
+// FilterCircle returns the Circle subtypes of the list in order.
+// Pointers to Circle are dereferenced and nil ones are left out.
+func (l ShapeList) FilterCircle() []Circle {
+	var filtered []Circle
+
+	for _, v := range l {
+		switch vv := v.IsShape.(type) {
+		case Circle:
+			filtered = append(filtered, vv)
+		case *Circle:
+			if vv != nil {
+				filtered = append(filtered, *vv)
+			}
+		}
+	}
+
+	return filtered
+}
+
+// FilterCircle returns the Circle subtypes of the map by their keys.
+// Pointers to Circle are dereferenced and nil ones are left out.
+func (m ShapeMap) FilterCircle() map[string]Circle {
+	filtered := make(map[string]Circle)
+
+	for k, v := range m {
+		switch vv := v.IsShape.(type) {
+		case Circle:
+			filtered[k] = vv
+		case *Circle:
+			if vv != nil {
+				filtered[k] = *vv
+			}
+		}
+	}
+
+	return filtered
+}
+
+// CountByCircle returns the number of Circle subtypes in the list, leaving out nil pointers to Circle.
+func (l ShapeList) CountByCircle() int {
+	var count int
+
+	for _, v := range l {
+		switch vv := v.IsShape.(type) {
+		case Circle:
+			count++
+		case *Circle:
+			if vv != nil {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// CountByCircle returns the number of Circle subtypes in the map, leaving out nil pointers to Circle.
+func (m ShapeMap) CountByCircle() int {
+	var count int
+
+	for _, v := range m {
+		switch vv := v.IsShape.(type) {
+		case Circle:
+			count++
+		case *Circle:
+			if vv != nil {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// FilterEmpty returns the Empty subtypes of the list in order.
+// Pointers to Empty are dereferenced and nil ones are left out.
+func (l ShapeList) FilterEmpty() []Empty {
+	var filtered []Empty
+
+	for _, v := range l {
+		switch vv := v.IsShape.(type) {
+		case Empty:
+			filtered = append(filtered, vv)
+		case *Empty:
+			if vv != nil {
+				filtered = append(filtered, *vv)
+			}
+		}
+	}
+
+	return filtered
+}
+
+// FilterEmpty returns the Empty subtypes of the map by their keys.
+// Pointers to Empty are dereferenced and nil ones are left out.
+func (m ShapeMap) FilterEmpty() map[string]Empty {
+	filtered := make(map[string]Empty)
+
+	for k, v := range m {
+		switch vv := v.IsShape.(type) {
+		case Empty:
+			filtered[k] = vv
+		case *Empty:
+			if vv != nil {
+				filtered[k] = *vv
+			}
+		}
+	}
+
+	return filtered
+}
+
+// CountByEmpty returns the number of Empty subtypes in the list, leaving out nil pointers to Empty.
+func (l ShapeList) CountByEmpty() int {
+	var count int
+
+	for _, v := range l {
+		switch vv := v.IsShape.(type) {
+		case Empty:
+			count++
+		case *Empty:
+			if vv != nil {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// CountByEmpty returns the number of Empty subtypes in the map, leaving out nil pointers to Empty.
+func (m ShapeMap) CountByEmpty() int {
+	var count int
+
+	for _, v := range m {
+		switch vv := v.IsShape.(type) {
+		case Empty:
+			count++
+		case *Empty:
+			if vv != nil {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// FilterGroup returns the *Group subtypes of the list in order.
+func (l ShapeList) FilterGroup() []*Group {
+	var filtered []*Group
+
+	for _, v := range l {
+		if vv, ok := v.IsShape.(*Group); ok {
+			filtered = append(filtered, vv)
+		}
+	}
+
+	return filtered
+}
+
+// FilterGroup returns the *Group subtypes of the map by their keys.
+func (m ShapeMap) FilterGroup() map[string]*Group {
+	filtered := make(map[string]*Group)
+
+	for k, v := range m {
+		if vv, ok := v.IsShape.(*Group); ok {
+			filtered[k] = vv
+		}
+	}
+
+	return filtered
+}
+
+// CountByGroup returns the number of *Group subtypes in the list.
+func (l ShapeList) CountByGroup() int {
+	var count int
+
+	for _, v := range l {
+		if _, ok := v.IsShape.(*Group); ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+// CountByGroup returns the number of *Group subtypes in the map.
+func (m ShapeMap) CountByGroup() int {
+	var count int
+
+	for _, v := range m {
+		if _, ok := v.IsShape.(*Group); ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+// FilterPolygon returns the *Polygon subtypes of the list in order.
+func (l ShapeList) FilterPolygon() []*Polygon {
+	var filtered []*Polygon
+
+	for _, v := range l {
+		if vv, ok := v.IsShape.(*Polygon); ok {
+			filtered = append(filtered, vv)
+		}
+	}
+
+	return filtered
+}
+
+// FilterPolygon returns the *Polygon subtypes of the map by their keys.
+func (m ShapeMap) FilterPolygon() map[string]*Polygon {
+	filtered := make(map[string]*Polygon)
+
+	for k, v := range m {
+		if vv, ok := v.IsShape.(*Polygon); ok {
+			filtered[k] = vv
+		}
+	}
+
+	return filtered
+}
+
+// CountByPolygon returns the number of *Polygon subtypes in the list.
+func (l ShapeList) CountByPolygon() int {
+	var count int
+
+	for _, v := range l {
+		if _, ok := v.IsShape.(*Polygon); ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+// CountByPolygon returns the number of *Polygon subtypes in the map.
+func (m ShapeMap) CountByPolygon() int {
+	var count int
+
+	for _, v := range m {
+		if _, ok := v.IsShape.(*Polygon); ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+// FilterRectangle returns the Rectangle subtypes of the list in order.
+// Pointers to Rectangle are dereferenced and nil ones are left out.
+func (l ShapeList) FilterRectangle() []Rectangle {
+	var filtered []Rectangle
+
+	for _, v := range l {
+		switch vv := v.IsShape.(type) {
+		case Rectangle:
+			filtered = append(filtered, vv)
+		case *Rectangle:
+			if vv != nil {
+				filtered = append(filtered, *vv)
+			}
+		}
+	}
+
+	return filtered
+}
+
+// FilterRectangle returns the Rectangle subtypes of the map by their keys.
+// Pointers to Rectangle are dereferenced and nil ones are left out.
+func (m ShapeMap) FilterRectangle() map[string]Rectangle {
+	filtered := make(map[string]Rectangle)
+
+	for k, v := range m {
+		switch vv := v.IsShape.(type) {
+		case Rectangle:
+			filtered[k] = vv
+		case *Rectangle:
+			if vv != nil {
+				filtered[k] = *vv
+			}
+		}
+	}
+
+	return filtered
+}
+
+// CountByRectangle returns the number of Rectangle subtypes in the list, leaving out nil pointers to Rectangle.
+func (l ShapeList) CountByRectangle() int {
+	var count int
+
+	for _, v := range l {
+		switch vv := v.IsShape.(type) {
+		case Rectangle:
+			count++
+		case *Rectangle:
+			if vv != nil {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// CountByRectangle returns the number of Rectangle subtypes in the map, leaving out nil pointers to Rectangle.
+func (m ShapeMap) CountByRectangle() int {
+	var count int
+
+	for _, v := range m {
+		switch vv := v.IsShape.(type) {
+		case Rectangle:
+			count++
+		case *Rectangle:
+			if vv != nil {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// UnmarshalJSON decodes a JSON array one element at a time, naming the index of a failing element.
+// encoding/json has read and validated the whole array before calling it, so this does not save memory;
+// only the jsonv2 UnmarshalJSONFrom and an arrayDecoder stream the input.
+func (l *ShapeList) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal ShapeList: %v", err)
+	}
+
+	if tok == nil {
+		*l = nil
+
+		return nil
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return errors.New("polygen: cannot unmarshal non-array into ShapeList")
+	}
+
+	list := ShapeList{}
+
+	for dec.More() {
+		var elem Shape
+		if err := dec.Decode(&elem); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal element %d of ShapeList: %v", len(list), err)
+		}
+
+		list = append(list, elem)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal ShapeList: %v", err)
+	}
+
+	*l = list
+
+	return nil
+}
+
+// UnmarshalJSON decodes a JSON object one entry at a time, naming the key of a failing value.
+// encoding/json has read and validated the whole object before calling it, so this does not save memory;
+// only the jsonv2 UnmarshalJSONFrom streams the input.
+func (m *ShapeMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal ShapeMap: %v", err)
+	}
+
+	if tok == nil {
+		*m = nil
+
+		return nil
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return errors.New("polygen: cannot unmarshal non-object into ShapeMap")
+	}
+
+	entries := ShapeMap{}
+
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal ShapeMap: %v", err)
+		}
+
+		key, _ := keyTok.(string) // Object keys are always strings
+
+		var elem Shape
+		if err := dec.Decode(&elem); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal key %q of ShapeMap: %v", key, err)
+		}
+
+		entries[key] = elem
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal ShapeMap: %v", err)
+	}
+
+	*m = entries
+
+	return nil
+}
@@ -0,0 +1,123 @@
+// Code generated by polygen; DO NOT EDIT.
+
+//go:build go1.20
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ShapeArrayDecoder decodes the elements of a JSON array of Shape one at a time, so that memory use
+// depends on the largest element rather than the whole array.
+type ShapeArrayDecoder struct {
+	dec     *json.Decoder
+	started bool
+	done    bool
+	index   int
+	value   Shape
+	err     error
+}
+
+// NewShapeArrayDecoder returns a decoder of the JSON array read from r. Input after the array is left unread
+// by the decoder, though it may be buffered.
+func NewShapeArrayDecoder(r io.Reader) *ShapeArrayDecoder {
+	dec := json.NewDecoder(r)
+
+	return &ShapeArrayDecoder{
+		dec:   dec,
+		index: -1,
+	}
+}
+
+// Next decodes the next element of the array, which Value then returns. It returns false at the end
+// of the array or on an error, which Err then returns. A null array has no elements.
+func (d *ShapeArrayDecoder) Next() bool {
+	if d.done {
+		return false
+	}
+
+	if !d.started {
+		d.started = true
+
+		if !d.start() {
+			d.done = true
+
+			return false
+		}
+	}
+
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			d.fail(fmt.Errorf("polygen: cannot decode end of Shape array at offset %d: %w", d.dec.InputOffset(), err))
+
+			return false
+		}
+
+		d.done = true
+		d.value = Shape{}
+
+		return false
+	}
+
+	d.index++
+	offset := d.dec.InputOffset()
+
+	var value Shape
+	if err := d.dec.Decode(&value); err != nil {
+		d.fail(fmt.Errorf("polygen: cannot decode element %d of Shape array at offset %d: %w", d.index, offset, err))
+
+		return false
+	}
+
+	d.value = value
+
+	return true
+}
+
+// start reads the opening bracket of the array, reporting whether there are elements to decode.
+func (d *ShapeArrayDecoder) start() bool {
+	tok, err := d.dec.Token()
+	if err != nil {
+		d.fail(fmt.Errorf("polygen: cannot decode Shape array at offset %d: %w", d.dec.InputOffset(), err))
+
+		return false
+	}
+
+	if tok == nil {
+		return false
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		d.fail(fmt.Errorf("polygen: cannot decode Shape array at offset %d: not an array", d.dec.InputOffset()))
+
+		return false
+	}
+
+	return true
+}
+
+// fail stops the decoder with err.
+func (d *ShapeArrayDecoder) fail(err error) {
+	d.done = true
+	d.value = Shape{}
+	d.err = err
+}
+
+// Value returns the element decoded by the last call to Next.
+func (d *ShapeArrayDecoder) Value() Shape {
+	return d.value
+}
+
+// Index returns the index in the array of the element decoded by the last call to Next.
+func (d *ShapeArrayDecoder) Index() int {
+	return d.index
+}
+
+// Err returns the error that stopped the decoder, if any. The error wraps the error of the element
+// or of the input, such as io.ErrUnexpectedEOF for an element cut off by the end of the input.
+func (d *ShapeArrayDecoder) Err() error {
+	return d.err
+}
@@ -0,0 +1,167 @@
+// Code generated by polygen; DO NOT EDIT.
+
+//go:build go1.20
+
+package tests
+
+import (
+	"reflect"
+)
+
+// Equal reports whether v and other hold equal subtypes, treating a subtype and a pointer to it as the same.
+// Subtypes are compared with their Equal method if they have one and with reflect.DeepEqual otherwise.
+func (v Shape) Equal(other Shape) bool {
+	switch vv := v.IsShape.(type) {
+	case nil:
+		return other.IsShape == nil
+	case Circle:
+		return _ShapeEqualCircle(&vv, other.IsShape)
+	case *Circle:
+		return _ShapeEqualCircle(vv, other.IsShape)
+	case Empty:
+		return _ShapeEqualEmpty(&vv, other.IsShape)
+	case *Empty:
+		return _ShapeEqualEmpty(vv, other.IsShape)
+	case *Group:
+		return _ShapeEqualGroup(vv, other.IsShape)
+	case *Polygon:
+		return _ShapeEqualPolygon(vv, other.IsShape)
+	case Rectangle:
+		return _ShapeEqualRectangle(&vv, other.IsShape)
+	case *Rectangle:
+		return _ShapeEqualRectangle(vv, other.IsShape)
+	default:
+		return reflect.DeepEqual(v.IsShape, other.IsShape)
+	}
+}
+
+func _ShapeEqualCircle(v *Circle, other IsShape) bool {
+	var o *Circle
+
+	switch oo := other.(type) {
+	case Circle:
+		o = &oo
+	case *Circle:
+		o = oo
+	default:
+		return false
+	}
+
+	if v == nil || o == nil {
+		return v == o
+	}
+
+	if eq, ok := any(*v).(interface{ Equal(Circle) bool }); ok {
+		return eq.Equal(*o)
+	}
+
+	if eq, ok := any(v).(interface{ Equal(*Circle) bool }); ok {
+		return eq.Equal(o)
+	}
+
+	return reflect.DeepEqual(*v, *o)
+}
+
+func _ShapeEqualEmpty(v *Empty, other IsShape) bool {
+	var o *Empty
+
+	switch oo := other.(type) {
+	case Empty:
+		o = &oo
+	case *Empty:
+		o = oo
+	default:
+		return false
+	}
+
+	if v == nil || o == nil {
+		return v == o
+	}
+
+	if eq, ok := any(*v).(interface{ Equal(Empty) bool }); ok {
+		return eq.Equal(*o)
+	}
+
+	if eq, ok := any(v).(interface{ Equal(*Empty) bool }); ok {
+		return eq.Equal(o)
+	}
+
+	return reflect.DeepEqual(*v, *o)
+}
+
+func _ShapeEqualGroup(v *Group, other IsShape) bool {
+	var o *Group
+
+	switch oo := other.(type) {
+	case *Group:
+		o = oo
+	default:
+		return false
+	}
+
+	if v == nil || o == nil {
+		return v == o
+	}
+
+	if eq, ok := any(*v).(interface{ Equal(Group) bool }); ok {
+		return eq.Equal(*o)
+	}
+
+	if eq, ok := any(v).(interface{ Equal(*Group) bool }); ok {
+		return eq.Equal(o)
+	}
+
+	return reflect.DeepEqual(*v, *o)
+}
+
+func _ShapeEqualPolygon(v *Polygon, other IsShape) bool {
+	var o *Polygon
+
+	switch oo := other.(type) {
+	case *Polygon:
+		o = oo
+	default:
+		return false
+	}
+
+	if v == nil || o == nil {
+		return v == o
+	}
+
+	if eq, ok := any(*v).(interface{ Equal(Polygon) bool }); ok {
+		return eq.Equal(*o)
+	}
+
+	if eq, ok := any(v).(interface{ Equal(*Polygon) bool }); ok {
+		return eq.Equal(o)
+	}
+
+	return reflect.DeepEqual(*v, *o)
+}
+
+func _ShapeEqualRectangle(v *Rectangle, other IsShape) bool {
+	var o *Rectangle
+
+	switch oo := other.(type) {
+	case Rectangle:
+		o = &oo
+	case *Rectangle:
+		o = oo
+	default:
+		return false
+	}
+
+	if v == nil || o == nil {
+		return v == o
+	}
+
+	if eq, ok := any(*v).(interface{ Equal(Rectangle) bool }); ok {
+		return eq.Equal(*o)
+	}
+
+	if eq, ok := any(v).(interface{ Equal(*Rectangle) bool }); ok {
+		return eq.Equal(o)
+	}
+
+	return reflect.DeepEqual(*v, *o)
+}
@@ -195,7 +195,7 @@ func (v *Shape) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
 			value = vv
 		}
 	default:
-		return fmt.Errorf("polygen: unknown subtype for Shape: %s", typeName)
+		return fmt.Errorf("polygen: unknown subtype for Shape: %v", typeName)
 	}
 
 	*v = Shape{
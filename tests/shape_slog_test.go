@@ -0,0 +1,94 @@
+//go:build go1.21
+
+package tests
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+// LogValue logs a polygon by its number of points, checking that the wrapper resolves the LogValuer of its subtype.
+func (p *Polygon) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("points", len(p.Points)))
+}
+
+func TestShapeLogValue(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		shape any
+		want  string
+	}{
+		{
+			name:  "circle",
+			shape: Shape{IsShape: Circle{Radius: 5}},
+			want:  "shape.type=circle shape.value={Radius:5}",
+		},
+		{
+			name:  "circle pointer",
+			shape: Shape{IsShape: &Circle{Radius: 5}},
+			want:  "shape.type=circle shape.value=&{Radius:5}",
+		},
+		{
+			name:  "subtype log valuer",
+			shape: Shape{IsShape: &Polygon{Points: make([]struct{ X, Y float64 }, 3)}},
+			want:  "shape.type=polygon shape.value.points=3",
+		},
+		{
+			name:  "int discriminator",
+			shape: ShapeCode{IsShape: Empty{}},
+			want:  "shape.type=3 shape.value={}",
+		},
+		{
+			name:  "nil",
+			shape: Shape{},
+			want:  "shape=<nil>",
+		},
+		{
+			name:  "unknown subtype",
+			shape: Shape{IsShape: Arc{Angle: 1}},
+			want:  "shape=\"{Kind: Angle:1}\"",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+						return slog.Attr{}
+					}
+
+					return a
+				},
+			}))
+			logger.Info("drawn", "shape", tt.shape)
+
+			if got := buf.String(); got != tt.want+"\n" {
+				t.Errorf("LogValue() logged %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShapeString(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		shape interface{ String() string }
+		want  string
+	}{
+		{name: "circle", shape: Shape{IsShape: Circle{Radius: 5}}, want: "circle{Radius:5}"},
+		{name: "circle pointer", shape: Shape{IsShape: &Circle{Radius: 5}}, want: "circle{Radius:5}"},
+		{name: "pointer subtype", shape: Shape{IsShape: &Polygon{Labels: []string{"a"}}}, want: "polygon{Points:[] Labels:[a]}"},
+		{name: "nil pointer subtype", shape: Shape{IsShape: (*Polygon)(nil)}, want: "polygon<nil>"},
+		{name: "int discriminator", shape: ShapeCode{IsShape: Rectangle{Width: 1}}, want: "2{Width:1 Height:0 Style:{Color: Fill:false}}"},
+		{name: "nil", shape: Shape{}, want: "<nil>"},
+		{name: "unknown subtype", shape: Shape{IsShape: Arc{Angle: 1}}, want: "{Kind: Angle:1}"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.shape.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestShapeSQL(t *testing.T) {
+	db := openFakeDB(t)
+
+	tests := []struct {
+		name  string
+		value Shape
+		want  any
+	}{
+		{
+			name:  "value",
+			value: Shape{IsShape: Circle{Radius: 5}},
+			want:  `{"type":"circle","Radius":5}`,
+		},
+		{
+			name:  "pointer",
+			value: Shape{IsShape: &Polygon{Labels: []string{"a"}}},
+			want:  `{"type":"polygon","Points":null,"Labels":["a"]}`,
+		},
+		{
+			name:  "nil",
+			value: Shape{},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := db.Exec("INSERT", tt.value); err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+
+			if stored := fakeDBRows(db); !reflect.DeepEqual(stored, []driver.Value{tt.want}) {
+				t.Errorf("Value() stored %#v, want %#v", stored, tt.want)
+			}
+
+			// Scanning replaces the value held before
+			got := Shape{IsShape: Rectangle{Width: 1}}
+			if err := db.QueryRow("SELECT").Scan(&got); err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.value)
+			}
+		})
+	}
+
+	t.Run("null policy", func(t *testing.T) {
+		if _, err := db.Exec("INSERT", nil); err != nil {
+			t.Fatalf("Exec() error = %v", err)
+		}
+
+		var got ShapeNullDefault
+		if err := db.QueryRow("SELECT").Scan(&got); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if want := (ShapeNullDefault{IsShape: Circle{}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("Scan() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("same subtype", func(t *testing.T) {
+		got := Shape{IsShape: Rectangle{Width: 1, Height: 7}}
+		if err := got.Scan(`{"type":"rectangle","Width":2}`); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if want := (Shape{IsShape: Rectangle{Width: 2}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("Scan() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("source types", func(t *testing.T) {
+		for _, src := range []any{[]byte(`{"type":"circle","Radius":5}`), `{"type":"circle","Radius":5}`} {
+			var got Shape
+			if err := got.Scan(src); err != nil {
+				t.Fatalf("Scan(%T) error = %v", src, err)
+			}
+			if want := (Shape{IsShape: Circle{Radius: 5}}); !reflect.DeepEqual(got, want) {
+				t.Errorf("Scan(%T) = %+v, want %+v", src, got, want)
+			}
+		}
+
+		var got Shape
+		if err := got.Scan(42); err == nil || err.Error() != "polygen: cannot scan int into Shape" {
+			t.Errorf("Scan(int) error = %v", err)
+		}
+		if err := got.Scan(`{"type":"square"}`); err == nil || !strings.Contains(err.Error(), "unknown subtype for Shape: square") {
+			t.Errorf("Scan(unknown) error = %v", err)
+		}
+	})
+}
+
+// fakeDriver is a database/sql driver storing the argument of each "INSERT" as the only row
+// returned by "SELECT", so that values go through the conversions of database/sql.
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows []driver.Value
+}
+
+var registerFakeDriver sync.Once
+
+func openFakeDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	registerFakeDriver.Do(func() {
+		sql.Register("polygen-fake", &fakeDriver{})
+	})
+
+	db, err := sql.Open("polygen-fake", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func fakeDBRows(db *sql.DB) []driver.Value {
+	d := db.Driver().(*fakeDriver)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.rows
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn{d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{c.driver, query}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	driver *fakeDriver
+	query  string
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (s fakeStmt) NumInput() int {
+	if s.query == "INSERT" {
+		return 1
+	}
+
+	return 0
+}
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.query != "INSERT" {
+		return nil, errors.New("unknown query " + s.query)
+	}
+
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+
+	s.driver.rows = []driver.Value{args[0]}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if s.query != "SELECT" {
+		return nil, errors.New("unknown query " + s.query)
+	}
+
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+
+	return &fakeRows{values: append([]driver.Value(nil), s.driver.rows...)}, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+}
+
+func (*fakeRows) Columns() []string {
+	return []string{"shape"}
+}
+
+func (*fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	// Drivers return JSON columns as bytes
+	if s, ok := r.values[0].(string); ok {
+		dest[0] = []byte(s)
+	} else {
+		dest[0] = r.values[0]
+	}
+
+	r.values = r.values[1:]
+
+	return nil
+}
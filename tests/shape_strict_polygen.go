@@ -307,7 +307,7 @@ func (v *ShapeStrict) UnmarshalJSON(data []byte) error {
 			value = vv.Rectangle
 		}
 	default:
-		return fmt.Errorf("polygen: unknown subtype for ShapeStrict: %s", typeName)
+		return fmt.Errorf("polygen: unknown subtype for ShapeStrict: %v", typeName)
 	}
 
 	*v = ShapeStrict{
@@ -0,0 +1,200 @@
+// Code generated by polygen; DO NOT EDIT.
+
+package tests
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ykalchevskiy/polygen/cbor"
+)
+
+var (
+	_ cbor.Marshaler   = ShapeStrict{}
+	_ cbor.Unmarshaler = (*ShapeStrict)(nil)
+)
+
+// MarshalCBOR encodes the subtype as a CBOR map with the type key holding its type name first.
+func (v ShapeStrict) MarshalCBOR() ([]byte, error) {
+	if v.IsShape == nil {
+		return cbor.Marshal(nil)
+	}
+
+	typeName, _, err := _ShapeStrictGetType(v.IsShape)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot get subtype to marshal for ShapeStrict: %v", err)
+	}
+
+	impl := v.IsShape
+
+	implData, err := cbor.Marshal(impl)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape for ShapeStrict: %v", err)
+	}
+
+	if cbor.IsNull(implData) {
+		return implData, nil
+	}
+
+	typeNameData, err := cbor.Marshal(typeName)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal discriminator type for ShapeStrict: %v", err)
+	}
+
+	data, err := cbor.InsertPath(implData, []string{"type"}, typeNameData)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape (%T) for ShapeStrict: %v", v.IsShape, err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalCBOR decodes the subtype named by the type key of the CBOR map.
+func (v *ShapeStrict) UnmarshalCBOR(data []byte) error {
+	if cbor.IsNull(data) {
+		*v = ShapeStrict{}
+
+		return nil
+	}
+
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeStrictGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeStrict: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	// First extract the type key, so that it does not get in the way of decoding the subtype
+	typeNameData, data, err := cbor.ExtractPath(data, []string{"type"})
+	if err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal discriminator type for ShapeStrict: %v", err)
+	}
+
+	typeName := currTypeName
+	if typeNameData != nil {
+		if err := cbor.Unmarshal(typeNameData, &typeName); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal discriminator type for ShapeStrict: %v", err)
+		}
+	}
+
+	if typeName == "" {
+		return errors.New("polygen: missing discriminator type for ShapeStrict")
+	}
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeStrict: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeStrict: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeStrict: %v", err)
+			}
+
+			value = vv
+		}
+	case "empty":
+		if currTypeName == "empty" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Empty)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeStrict: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Empty)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeStrict: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Empty
+			if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Empty for ShapeStrict: %v", err)
+			}
+
+			value = vv
+		}
+	case "group":
+		var vv *Group
+		if currTypeName == "group" {
+			vv = v.IsShape.(*Group)
+		}
+		if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Group for ShapeStrict: %v", err)
+		}
+
+		value = vv
+	case "polygon":
+		var vv *Polygon
+		if currTypeName == "polygon" {
+			vv = v.IsShape.(*Polygon)
+		}
+		if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Polygon for ShapeStrict: %v", err)
+		}
+
+		value = vv
+	case "rectangle":
+		if currTypeName == "rectangle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Rectangle)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeStrict: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Rectangle)
+				if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeStrict: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Rectangle
+			if err := cbor.UnmarshalStrict(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeStrict: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeStrict: %v", typeName)
+	}
+
+	*v = ShapeStrict{
+		IsShape: value,
+	}
+
+	return nil
+}
@@ -195,7 +195,7 @@ func (v *ShapeStrict) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
 			value = vv
 		}
 	default:
-		return fmt.Errorf("polygen: unknown subtype for ShapeStrict: %s", typeName)
+		return fmt.Errorf("polygen: unknown subtype for ShapeStrict: %v", typeName)
 	}
 
 	*v = ShapeStrict{
@@ -0,0 +1,206 @@
+// Code generated by polygen; DO NOT EDIT.
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	_ IsShape = Circle{}
+	_ IsShape = (*Polygon)(nil)
+	_ IsShape = Rectangle{}
+)
+
+// _ShapeValidatedTypeRegistry maps concrete types to their type names.
+var _ShapeValidatedTypeRegistry = map[reflect.Type]string{
+	reflect.TypeOf((*Circle)(nil)).Elem():    "circle",
+	reflect.TypeOf((*Polygon)(nil)):          "polygon",
+	reflect.TypeOf((*Rectangle)(nil)).Elem(): "rectangle",
+}
+
+type ShapeValidated struct {
+	IsShape
+}
+
+func (v ShapeValidated) MarshalJSON() ([]byte, error) {
+	if v.IsShape == nil {
+		return []byte("null"), nil
+	}
+
+	// Marshal the implementation first to get its fields
+	implData, err := json.Marshal(v.IsShape)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot marshal IsShape for ShapeValidated: %v", err)
+	}
+
+	if bytes.Equal(implData, []byte("null")) {
+		return implData, nil
+	}
+
+	typeName, _, err := _ShapeValidatedGetType(v.IsShape)
+	if err != nil {
+		return nil, fmt.Errorf("polygen: cannot get subtype to marshal for ShapeValidated: %v", err)
+	}
+
+	// If it's an empty object, just return discriminator
+	if bytes.Equal(implData, []byte("{}")) {
+		return []byte(`{"type":"` + typeName + `"}`), nil
+	}
+
+	if len(implData) == 0 || implData[0] != '{' {
+		return nil, fmt.Errorf("polygen: expected JSON object for IsShape (%T), got %s", v.IsShape, implData)
+	}
+
+	// Otherwise, combine discriminator with implementation fields
+	var buf bytes.Buffer
+
+	buf.Grow(len(`{"type":"",`) + len(typeName) + len(implData) - 1)
+	buf.WriteString(`{"type":"`)
+	buf.WriteString(typeName)
+	buf.WriteString(`",`)
+	buf.Write(implData[1:])
+
+	return buf.Bytes(), nil
+}
+
+func (v *ShapeValidated) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*v = ShapeValidated{}
+
+		return nil
+	}
+
+	var (
+		currTypeName      string
+		currTypeAsPointer bool
+	)
+
+	if v.IsShape != nil {
+		var err error
+
+		currTypeName, currTypeAsPointer, err = _ShapeValidatedGetType(v.IsShape)
+		if err != nil {
+			return fmt.Errorf("polygen: cannot get subtype to unmarshal for ShapeValidated: %v", err)
+		}
+	}
+
+	_ = currTypeAsPointer // In case of all subtypes being pointers, we must just ignore this
+
+	// First decode just the type field
+	typeData := struct {
+		TypeName string `json:"type"`
+	}{
+		TypeName: currTypeName,
+	}
+	if err := json.Unmarshal(data, &typeData); err != nil {
+		return fmt.Errorf("polygen: cannot unmarshal discriminator type for ShapeValidated: %v", err)
+	}
+
+	if typeData.TypeName == "" {
+		return errors.New("polygen: missing discriminator type for ShapeValidated")
+	}
+
+	typeName := typeData.TypeName
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		if currTypeName == "circle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Circle)
+				if err := json.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeValidated: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Circle)
+				if err := json.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeValidated: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Circle
+			if err := json.Unmarshal(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Circle for ShapeValidated: %v", err)
+			}
+
+			value = vv
+		}
+	case "polygon":
+		var vv *Polygon
+		if currTypeName == "polygon" {
+			vv = v.IsShape.(*Polygon)
+		}
+		if err := json.Unmarshal(data, &vv); err != nil {
+			return fmt.Errorf("polygen: cannot unmarshal Polygon for ShapeValidated: %v", err)
+		}
+
+		value = vv
+	case "rectangle":
+		if currTypeName == "rectangle" {
+			if currTypeAsPointer {
+				vv := v.IsShape.(*Rectangle)
+				if err := json.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeValidated: %v", err)
+				}
+
+				value = vv
+			} else {
+				vv := v.IsShape.(Rectangle)
+				if err := json.Unmarshal(data, &vv); err != nil {
+					return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeValidated: %v", err)
+				}
+
+				value = vv
+			}
+		} else {
+			var vv Rectangle
+			if err := json.Unmarshal(data, &vv); err != nil {
+				return fmt.Errorf("polygen: cannot unmarshal Rectangle for ShapeValidated: %v", err)
+			}
+
+			value = vv
+		}
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeValidated: %v", typeName)
+	}
+
+	if validator, ok := value.(interface{ Validate() error }); ok {
+		if err := validator.Validate(); err != nil {
+			return fmt.Errorf("polygen: invalid %T for ShapeValidated: %w", value, err)
+		}
+	}
+
+	*v = ShapeValidated{
+		IsShape: value,
+	}
+
+	return nil
+}
+
+func _ShapeValidatedGetType(v IsShape) (name string, asPointer bool, _ error) {
+	t := reflect.TypeOf(v)
+
+	typeName, ok := _ShapeValidatedTypeRegistry[t]
+	if ok {
+		return typeName, false, nil
+	}
+
+	// A pointer can be manually used for a value type as it also implements the interface
+	if t.Kind() == reflect.Pointer {
+		typeName, ok = _ShapeValidatedTypeRegistry[t.Elem()]
+		if ok {
+			return typeName, true, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("unknown subtype: %v", t)
+}
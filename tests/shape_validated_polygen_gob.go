@@ -0,0 +1,103 @@
+// Code generated by polygen; DO NOT EDIT.
+
+package tests
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// RegisterShapeValidatedGob registers the subtypes of ShapeValidated with encoding/gob under stable names derived from
+// their type names, so that they can be encoded as IsShape values, e.g. over net/rpc.
+// Like gob.RegisterName, it panics if a subtype is already registered under another name.
+func RegisterShapeValidatedGob() {
+	gob.RegisterName("tests.circle", Circle{})
+	gob.RegisterName("tests.polygon", (*Polygon)(nil))
+	gob.RegisterName("tests.rectangle", Rectangle{})
+}
+
+// GobEncode encodes the type name of the subtype followed by the subtype itself, so that no registration is needed.
+func (v ShapeValidated) GobEncode() ([]byte, error) {
+	var typeName string
+
+	if v.IsShape != nil {
+		var err error
+
+		typeName, _, err = _ShapeValidatedGetType(v.IsShape)
+		if err != nil {
+			return nil, fmt.Errorf("polygen: cannot get subtype to marshal for ShapeValidated: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	encoder := gob.NewEncoder(&buf)
+	if err := encoder.Encode(typeName); err != nil {
+		return nil, fmt.Errorf("polygen: cannot encode discriminator for ShapeValidated: %v", err)
+	}
+
+	if v.IsShape != nil {
+		if err := encoder.Encode(v.IsShape); err != nil {
+			return nil, fmt.Errorf("polygen: cannot encode IsShape for ShapeValidated: %v", err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GobDecode decodes the subtype named by the type name GobEncode wrote before it.
+func (v *ShapeValidated) GobDecode(data []byte) error {
+	decoder := gob.NewDecoder(bytes.NewReader(data))
+
+	var typeName string
+	if err := decoder.Decode(&typeName); err != nil {
+		return fmt.Errorf("polygen: cannot decode discriminator for ShapeValidated: %v", err)
+	}
+
+	if typeName == "" {
+		*v = ShapeValidated{}
+
+		return nil
+	}
+
+	var value IsShape
+
+	switch typeName {
+	case "circle":
+		var vv Circle
+		if err := decoder.Decode(&vv); err != nil {
+			return fmt.Errorf("polygen: cannot decode Circle for ShapeValidated: %v", err)
+		}
+
+		value = vv
+	case "polygon":
+		vv := new(Polygon)
+		if err := decoder.Decode(vv); err != nil {
+			return fmt.Errorf("polygen: cannot decode Polygon for ShapeValidated: %v", err)
+		}
+
+		value = vv
+	case "rectangle":
+		var vv Rectangle
+		if err := decoder.Decode(&vv); err != nil {
+			return fmt.Errorf("polygen: cannot decode Rectangle for ShapeValidated: %v", err)
+		}
+
+		value = vv
+	default:
+		return fmt.Errorf("polygen: unknown subtype for ShapeValidated: %v", typeName)
+	}
+
+	if validator, ok := value.(interface{ Validate() error }); ok {
+		if err := validator.Validate(); err != nil {
+			return fmt.Errorf("polygen: invalid %T for ShapeValidated: %w", value, err)
+		}
+	}
+
+	*v = ShapeValidated{
+		IsShape: value,
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShapeValidated(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    ShapeValidated
+		wantErr string
+	}{
+		{
+			name: "without validate method",
+			json: `{"type":"circle","Radius":-1}`,
+			want: ShapeValidated{IsShape: Circle{Radius: -1}},
+		},
+		{
+			name: "valid value",
+			json: `{"type":"rectangle","Width":1,"Height":2}`,
+			want: ShapeValidated{IsShape: Rectangle{Width: 1, Height: 2}},
+		},
+		{
+			name:    "invalid value",
+			json:    `{"type":"rectangle","Width":-1,"Height":2}`,
+			wantErr: "polygen: invalid tests.Rectangle for ShapeValidated: negative size -1x2",
+		},
+		{
+			name:    "invalid pointer",
+			json:    `{"type":"polygon","Labels":["a",""]}`,
+			wantErr: "polygen: invalid *tests.Polygon for ShapeValidated: empty label",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ShapeValidated
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if tt.wantErr != "" {
+				// jsonv2 wraps the error with its own context
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("UnmarshalJSON() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
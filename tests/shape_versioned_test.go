@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestShapeVersioned(t *testing.T) {
+	t.Run("marshal", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			shape ShapeVersioned
+			want  string
+		}{
+			{
+				name:  "versioned",
+				shape: ShapeVersioned{IsShape: Circle{Radius: 5}},
+				want:  `{"type":"circle","v":2,"Radius":5}`,
+			},
+			{
+				name:  "unversioned",
+				shape: ShapeVersioned{IsShape: Empty{}},
+				want:  `{"type":"empty"}`,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := json.Marshal(tt.shape)
+				if err != nil {
+					t.Fatalf("MarshalJSON() error = %v", err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			json    string
+			want    ShapeVersioned
+			wantErr bool
+		}{
+			{
+				name: "current version",
+				json: `{"type":"circle","v":2,"Radius":5}`,
+				want: ShapeVersioned{IsShape: Circle{Radius: 5}},
+			},
+			{
+				name: "upgrade version 1",
+				json: `{"type":"circle","v":1,"r":5}`,
+				want: ShapeVersioned{IsShape: Circle{Radius: 5}},
+			},
+			{
+				name: "upgrade missing version",
+				json: `{"type":"circle","r":5}`,
+				want: ShapeVersioned{IsShape: Circle{Radius: 5}},
+			},
+			{
+				name:    "newer version",
+				json:    `{"type":"circle","v":3,"Radius":5}`,
+				wantErr: true,
+			},
+			{
+				name: "older version without upgrade",
+				json: `{"type":"rectangle","Width":1,"Height":2}`,
+				want: ShapeVersioned{IsShape: Rectangle{Width: 1, Height: 2}},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// ShapeVersioned is strict, the decoder makes it so for jsonv2 as well
+				var got ShapeVersioned
+				dec := json.NewDecoder(bytes.NewReader([]byte(tt.json)))
+				dec.DisallowUnknownFields()
+				err := dec.Decode(&got)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err == nil && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("partial update", func(t *testing.T) {
+		tests := []struct {
+			name string
+			json string
+			want ShapeVersioned
+		}{
+			{
+				name: "missing version",
+				json: `{"Radius":5}`,
+				want: ShapeVersioned{IsShape: Circle{Radius: 5}},
+			},
+			{
+				name: "older version",
+				json: `{"v":1,"r":5}`,
+				want: ShapeVersioned{IsShape: Circle{Radius: 5}},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// The circle held before is updated, so data without a version is taken as current
+				got := ShapeVersioned{IsShape: Circle{Radius: 1}}
+				if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+					t.Fatalf("UnmarshalJSON() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestShapeAttributed(t *testing.T) {
+	type drawing struct {
+		XMLName xml.Name          `xml:"drawing"`
+		Shapes  []ShapeAttributed `xml:"shape"`
+	}
+
+	value := drawing{
+		XMLName: xml.Name{Local: "drawing"},
+		Shapes: []ShapeAttributed{
+			{IsShape: Circle{Radius: 5}},
+			{IsShape: &Polygon{Labels: []string{"a", "b"}}},
+			{IsShape: Empty{}},
+		},
+	}
+	data := `<drawing>` +
+		`<shape type="circle"><Radius>5</Radius></shape>` +
+		`<shape type="polygon"><Labels>a</Labels><Labels>b</Labels></shape>` +
+		`<shape type="empty"></shape>` +
+		`</drawing>`
+
+	t.Run("marshal", func(t *testing.T) {
+		got, err := xml.Marshal(value)
+		if err != nil {
+			t.Fatalf("MarshalXML() error = %v", err)
+		}
+		if string(got) != data {
+			t.Errorf("MarshalXML() = %s, want %s", got, data)
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var got drawing
+		if err := xml.Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("UnmarshalXML() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, value) {
+			t.Errorf("UnmarshalXML() = %+v, want %+v", got, value)
+		}
+	})
+
+	t.Run("marshal nil", func(t *testing.T) {
+		got, err := xml.Marshal(drawing{Shapes: []ShapeAttributed{{}}})
+		if err != nil {
+			t.Fatalf("MarshalXML() error = %v", err)
+		}
+		if want := `<drawing></drawing>`; string(got) != want {
+			t.Errorf("MarshalXML() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		got := ShapeAttributed{IsShape: Rectangle{Width: 1, Height: 2}}
+		if err := xml.Unmarshal([]byte(`<shape><Height>3</Height></shape>`), &got); err != nil {
+			t.Fatalf("UnmarshalXML() error = %v", err)
+		}
+		if want := (ShapeAttributed{IsShape: Rectangle{Width: 1, Height: 3}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalXML() = %+v, want %+v", got, want)
+		}
+	})
+
+	for _, tt := range []struct {
+		name    string
+		xml     string
+		wantErr string
+	}{
+		{
+			name:    "missing attribute",
+			xml:     `<shape><Radius>5</Radius></shape>`,
+			wantErr: "polygen: missing discriminator attribute type for ShapeAttributed",
+		},
+		{
+			name:    "unknown subtype",
+			xml:     `<shape type="square"></shape>`,
+			wantErr: "polygen: unknown subtype for ShapeAttributed: square",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ShapeAttributed
+			if err := xml.Unmarshal([]byte(tt.xml), &got); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("UnmarshalXML() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShapeElement(t *testing.T) {
+	type drawing struct {
+		XMLName xml.Name       `xml:"drawing"`
+		Shapes  []ShapeElement `xml:",any"`
+	}
+
+	value := drawing{
+		XMLName: xml.Name{Local: "drawing"},
+		Shapes: []ShapeElement{
+			{IsShape: Rectangle{Width: 1, Height: 2}},
+			{IsShape: &Polygon{Labels: []string{"a"}}},
+		},
+	}
+	data := `<drawing>` +
+		`<rectangle><Width>1</Width><Height>2</Height><Style><Color></Color><Fill>false</Fill></Style></rectangle>` +
+		`<polygon><Labels>a</Labels></polygon>` +
+		`</drawing>`
+
+	t.Run("marshal", func(t *testing.T) {
+		got, err := xml.Marshal(value)
+		if err != nil {
+			t.Fatalf("MarshalXML() error = %v", err)
+		}
+		if string(got) != data {
+			t.Errorf("MarshalXML() = %s, want %s", got, data)
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var got drawing
+		if err := xml.Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("UnmarshalXML() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, value) {
+			t.Errorf("UnmarshalXML() = %+v, want %+v", got, value)
+		}
+	})
+
+	t.Run("unknown subtype", func(t *testing.T) {
+		var got drawing
+		err := xml.Unmarshal([]byte(`<drawing><square></square></drawing>`), &got)
+		if want := "polygen: unknown subtype for ShapeElement: square"; err == nil || err.Error() != want {
+			t.Errorf("UnmarshalXML() error = %v, want %q", err, want)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestShapeYAML(t *testing.T) {
+	type drawing struct {
+		Shapes []Shape `yaml:"shapes"`
+	}
+
+	value := drawing{
+		Shapes: []Shape{
+			{IsShape: Circle{Radius: 5}},
+			{IsShape: &Polygon{Labels: []string{"a", "b"}}},
+			{IsShape: Empty{}},
+		},
+	}
+	data := `shapes:
+    - type: circle
+      radius: 5
+    - type: polygon
+      points: []
+      labels:
+        - a
+        - b
+    - type: empty
+`
+
+	t.Run("marshal", func(t *testing.T) {
+		got, err := yaml.Marshal(value)
+		if err != nil {
+			t.Fatalf("MarshalYAML() error = %v", err)
+		}
+		if string(got) != data {
+			t.Errorf("MarshalYAML() = %s, want %s", got, data)
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var got drawing
+		if err := yaml.Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("UnmarshalYAML() error = %v", err)
+		}
+		value.Shapes[1].IsShape.(*Polygon).Points = []struct {
+			X float64
+			Y float64
+		}{}
+		if !reflect.DeepEqual(got, value) {
+			t.Errorf("UnmarshalYAML() = %+v, want %+v", got, value)
+		}
+	})
+
+	t.Run("marshal nil", func(t *testing.T) {
+		got, err := yaml.Marshal(drawing{Shapes: []Shape{{}}})
+		if err != nil {
+			t.Fatalf("MarshalYAML() error = %v", err)
+		}
+		if want := "shapes:\n    - null\n"; string(got) != want {
+			t.Errorf("MarshalYAML() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		got := Shape{IsShape: Rectangle{Width: 1, Height: 2}}
+		if err := yaml.Unmarshal([]byte(`height: 3`), &got); err != nil {
+			t.Fatalf("UnmarshalYAML() error = %v", err)
+		}
+		if want := (Shape{IsShape: Rectangle{Width: 1, Height: 3}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalYAML() = %+v, want %+v", got, want)
+		}
+	})
+
+	for _, tt := range []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name:    "missing discriminator",
+			yaml:    `radius: 5`,
+			wantErr: "polygen: missing discriminator type for Shape",
+		},
+		{
+			name:    "unknown subtype",
+			yaml:    `type: square`,
+			wantErr: "polygen: unknown subtype for Shape: square",
+		},
+		{
+			name:    "not a mapping",
+			yaml:    `[circle]`,
+			wantErr: "polygen: expected YAML mapping for Shape, got !!seq",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Shape
+			if err := yaml.Unmarshal([]byte(tt.yaml), &got); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("UnmarshalYAML() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShapeYAML_mergeKeys(t *testing.T) {
+	type drawing struct {
+		Base   map[string]any `yaml:"base"`
+		Shapes []Shape        `yaml:"shapes"`
+	}
+
+	data := `base: &base
+    type: circle
+    radius: 1
+shapes:
+    - <<: *base
+    - <<: *base
+      radius: 5
+    - <<: [{type: rectangle, width: 2}, *base]
+      height: 3
+`
+
+	var got drawing
+	if err := yaml.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("UnmarshalYAML() error = %v", err)
+	}
+
+	want := []Shape{
+		{IsShape: Circle{Radius: 1}},
+		{IsShape: Circle{Radius: 5}},
+		{IsShape: Rectangle{Width: 2, Height: 3}},
+	}
+	if !reflect.DeepEqual(got.Shapes, want) {
+		t.Errorf("UnmarshalYAML() = %+v, want %+v", got.Shapes, want)
+	}
+}
+
+func TestShapeNestedYAML_mergeKeys(t *testing.T) {
+	data := `- meta: &meta
+      <<: {type: circle}
+  radius: 5
+- meta: *meta
+  radius: 1
+`
+
+	var got []ShapeNested
+	if err := yaml.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("UnmarshalYAML() error = %v", err)
+	}
+
+	want := []ShapeNested{
+		{IsShape: Circle{Radius: 5}},
+		{IsShape: Circle{Radius: 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalYAML() = %+v, want %+v", got, want)
+	}
+}
+
+func TestShapeDefaultYAML(t *testing.T) {
+	var got ShapeDefault
+	if err := yaml.Unmarshal([]byte(`radius: 5`), &got); err != nil {
+		t.Fatalf("UnmarshalYAML() error = %v", err)
+	}
+	if want := (ShapeDefault{IsShape: Circle{Radius: 5}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalYAML() = %+v, want %+v", got, want)
+	}
+}
+
+func TestShapeStrictYAML(t *testing.T) {
+	var got ShapeStrict
+	if err := yaml.Unmarshal([]byte("type: circle\nradius: 5"), &got); err != nil {
+		t.Fatalf("UnmarshalYAML() error = %v", err)
+	}
+	if want := (ShapeStrict{IsShape: Circle{Radius: 5}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalYAML() = %+v, want %+v", got, want)
+	}
+
+	err := yaml.Unmarshal([]byte("type: circle\nradius: 5\ncolor: red"), &got)
+	if err == nil || !strings.Contains(err.Error(), "field color not found") {
+		t.Errorf("UnmarshalYAML() error = %v, want unknown field error", err)
+	}
+}
+
+func TestShapeNestedYAML(t *testing.T) {
+	value := ShapeNested{IsShape: Label{Text: "hi"}}
+	data := "text: hi\nmeta:\n    type: label\n    author: \"\"\n"
+
+	got, err := yaml.Marshal(value)
+	if err != nil {
+		t.Fatalf("MarshalYAML() error = %v", err)
+	}
+	if string(got) != data {
+		t.Errorf("MarshalYAML() = %q, want %q", got, data)
+	}
+
+	var decoded ShapeNested
+	if err := yaml.Unmarshal([]byte("meta:\n    type: circle\nradius: 5"), &decoded); err != nil {
+		t.Fatalf("UnmarshalYAML() error = %v", err)
+	}
+	if want := (ShapeNested{IsShape: Circle{Radius: 5}}); !reflect.DeepEqual(decoded, want) {
+		t.Errorf("UnmarshalYAML() = %+v, want %+v", decoded, want)
+	}
+}
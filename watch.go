@@ -0,0 +1,227 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ykalchevskiy/polygen/gen"
+)
+
+// watchInterval is how often the watched files are polled for changes.
+const watchInterval = 500 * time.Millisecond
+
+// fileStamp identifies a version of a watched file.
+type fileStamp struct {
+	ModTime time.Time
+	Size    int64
+}
+
+func (f fileStamp) equal(other fileStamp) bool {
+	return f.ModTime.Equal(other.ModTime) && f.Size == other.Size
+}
+
+// snapshot is the state of all watched files at some point in time.
+type snapshot struct {
+	// Config is the stamp of the config file
+	Config fileStamp
+	// Files maps Go source files in the packages of the configured types to their stamps
+	Files map[string]fileStamp
+	// Templates maps the user-supplied template files of the configured types to their stamps
+	Templates map[string]fileStamp
+	// Types maps package directories to the names of the types generated into them
+	Types map[string][]string
+}
+
+// watch generates the code once and then polls the config file and the packages of the configured types,
+// regenerating the affected types once changes settle down. Errors are logged and watching goes on.
+// It returns when stop is closed; a nil stop means watching forever. If ready is not nil, it is sent
+// to each time a snapshot is taken that later changes are detected against, i.e. after every generation.
+func watch(configPath string, opts runOptions, interval time.Duration, stop <-chan struct{}, ready chan<- struct{}) error {
+	if err := generateFromConfig(configPath, opts, nil); err != nil {
+		log.Printf("Failed to generate: %v", err)
+	}
+
+	log.Printf("Watching %s for changes", configPath)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := takeSnapshot(configPath)
+
+	for {
+		if ready != nil {
+			select {
+			case <-stop:
+				return nil
+			case ready <- struct{}{}:
+			}
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		curr := takeSnapshot(configPath)
+		if curr.equal(prev) {
+			continue
+		}
+
+		// Debounce: wait for a poll without any further changes
+		for {
+			select {
+			case <-stop:
+				return nil
+			case <-ticker.C:
+			}
+
+			next := takeSnapshot(configPath)
+			if next.equal(curr) {
+				break
+			}
+
+			curr = next
+		}
+
+		only := affectedTypes(prev, curr)
+		if only == nil {
+			log.Printf("Config or templates changed, regenerating all types")
+		} else {
+			log.Printf("Sources changed, regenerating %d type(s)", len(only))
+		}
+
+		if err := generateFromConfig(configPath, opts, only); err != nil {
+			log.Printf("Failed to generate: %v", err)
+		}
+
+		// Generated files are not watched, so this only catches edits made while generating
+		prev = curr
+	}
+}
+
+// takeSnapshot stats the config file, the user-supplied templates and the Go files in the package directories
+// of the configured types.
+// Generated files are left out so that writing them does not trigger another round.
+// If the config cannot be loaded, only the config file itself is watched.
+func takeSnapshot(configPath string) snapshot {
+	s := snapshot{
+		Files:     make(map[string]fileStamp),
+		Templates: make(map[string]fileStamp),
+		Types:     make(map[string][]string),
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return s
+	}
+
+	s.Config = fileStamp{ModTime: info.ModTime(), Size: info.Size()}
+
+	config, err := gen.LoadConfig(configPath)
+	if err != nil {
+		return s
+	}
+
+	generated := make(map[string]bool)
+
+	for i := range config.Types {
+		outputPaths := gen.OutputPaths(config, &config.Types[i])
+
+		for _, outputPath := range outputPaths {
+			generated[outputPath] = true
+		}
+
+		dir := filepath.Dir(outputPaths[0])
+		s.Types[dir] = append(s.Types[dir], config.Types[i].Type)
+
+		for _, tmplConfig := range config.Types[i].Templates {
+			path := filepath.Join(config.Dir, tmplConfig.Path)
+
+			// A missing template keeps a zero stamp, so that creating it is noticed
+			var stamp fileStamp
+			if info, err := os.Stat(path); err == nil {
+				stamp = fileStamp{ModTime: info.ModTime(), Size: info.Size()}
+			}
+
+			s.Templates[path] = stamp
+		}
+	}
+
+	for dir := range s.Types {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+
+			if entry.IsDir() || !strings.HasSuffix(path, ".go") || generated[path] {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			s.Files[path] = fileStamp{ModTime: info.ModTime(), Size: info.Size()}
+		}
+	}
+
+	return s
+}
+
+func (s snapshot) equal(other snapshot) bool {
+	return s.Config.equal(other.Config) && stampsEqual(s.Files, other.Files) && stampsEqual(s.Templates, other.Templates)
+}
+
+func stampsEqual(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for path, stamp := range a {
+		if otherStamp, ok := b[path]; !ok || !stamp.equal(otherStamp) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// affectedTypes returns the names of the types whose packages have changed files between prev and curr.
+// It returns nil if the config file or a template has changed, meaning all types are affected.
+func affectedTypes(prev, curr snapshot) map[string]bool {
+	if !prev.Config.equal(curr.Config) || !stampsEqual(prev.Templates, curr.Templates) {
+		return nil
+	}
+
+	changedDirs := make(map[string]bool)
+
+	for path, stamp := range curr.Files {
+		if prevStamp, ok := prev.Files[path]; !ok || !stamp.equal(prevStamp) {
+			changedDirs[filepath.Dir(path)] = true
+		}
+	}
+
+	for path := range prev.Files {
+		if _, ok := curr.Files[path]; !ok {
+			changedDirs[filepath.Dir(path)] = true
+		}
+	}
+
+	only := make(map[string]bool)
+
+	for dir := range changedDirs {
+		for _, typeName := range curr.Types[dir] {
+			only[typeName] = true
+		}
+	}
+
+	return only
+}
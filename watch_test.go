@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, ".polygen.json")
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"subtypes": {
+				"ItemValue1": {}
+			}
+		}
+	]
+}`)
+
+	stop := make(chan struct{})
+	ready := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		done <- watch(configFile, runOptions{}, 10*time.Millisecond, stop, ready)
+	}()
+
+	genFile := filepath.Join(tempDir, "item_value_polygen.go")
+
+	// Wait for the watcher to take its first snapshot before changing the config
+	waitForReady(t, ready)
+	waitForContent(t, genFile, `case "item-value-1":`)
+
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"subtypes": {
+				"ItemValue1": {},
+				"ItemValue2": {}
+			}
+		}
+	]
+}`)
+
+	waitForReady(t, ready)
+	waitForContent(t, genFile, `case "item-value-2":`)
+
+	// An invalid config must not stop watching
+	createFile(t, configFile, `{`)
+	waitForReady(t, ready)
+
+	createFile(t, configFile, `{
+	"types": [
+		{
+			"type": "ItemValue",
+			"interface": "IsItemValue",
+			"package": "pkg",
+			"subtypes": {
+				"ItemValue3": {}
+			}
+		}
+	]
+}`)
+
+	waitForContent(t, genFile, `case "item-value-3":`)
+
+	close(stop)
+
+	if err := <-done; err != nil {
+		t.Errorf("watch() returned error: %v", err)
+	}
+}
+
+func Test_affectedTypes(t *testing.T) {
+	stamp := fileStamp{ModTime: time.Unix(1, 0), Size: 1}
+	changed := fileStamp{ModTime: time.Unix(2, 0), Size: 1}
+
+	types := map[string][]string{
+		"a": {"A1", "A2"},
+		"b": {"B"},
+		"c": {"C"},
+	}
+
+	prev := snapshot{
+		Config: stamp,
+		Files: map[string]fileStamp{
+			filepath.Join("a", "a.go"): stamp,
+			filepath.Join("b", "b.go"): stamp,
+			filepath.Join("c", "c.go"): stamp,
+		},
+		Types: types,
+	}
+
+	t.Run("config changed", func(t *testing.T) {
+		curr := prev
+		curr.Config = changed
+
+		if got := affectedTypes(prev, curr); got != nil {
+			t.Errorf("affectedTypes() = %v, want nil", got)
+		}
+	})
+
+	t.Run("template changed", func(t *testing.T) {
+		prev := prev
+		prev.Templates = map[string]fileStamp{"a.go.tmpl": stamp}
+
+		curr := prev
+		curr.Templates = map[string]fileStamp{"a.go.tmpl": changed}
+
+		if got := affectedTypes(prev, curr); got != nil {
+			t.Errorf("affectedTypes() = %v, want nil", got)
+		}
+	})
+
+	t.Run("files changed", func(t *testing.T) {
+		curr := snapshot{
+			Config: stamp,
+			Files: map[string]fileStamp{
+				filepath.Join("a", "a.go"): changed,
+				filepath.Join("b", "b.go"): stamp,
+			},
+			Types: types,
+		}
+
+		want := map[string]bool{"A1": true, "A2": true, "C": true}
+		if got := affectedTypes(prev, curr); !reflect.DeepEqual(got, want) {
+			t.Errorf("affectedTypes() = %v, want %v", got, want)
+		}
+	})
+}
+
+func waitForReady(t *testing.T, ready <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-ready:
+	case <-time.After(10 * time.Second):
+		t.Fatal("watcher did not take a snapshot in time")
+	}
+}
+
+func waitForContent(t *testing.T, path, content string) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+
+	for time.Now().Before(deadline) {
+		if data, err := os.ReadFile(path); err == nil && bytes.Contains(data, []byte(content)) {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("file %s does not contain %q in time", path, content)
+}
+
+func createFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to create file %s: %v", path, err)
+	}
+}